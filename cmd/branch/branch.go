@@ -8,24 +8,45 @@ import (
 	"github.com/recrsn/git-ai/pkg/llm"
 	"github.com/recrsn/git-ai/pkg/logger"
 	"github.com/recrsn/git-ai/pkg/ui"
-	"os"
 	"strings"
 )
 
 func executeBranch(description, diff string) {
 	cfg := config.LoadConfigOrFatal()
 
+	// Leave out generated and excluded files, and mask secrets before anything is sent to the LLM
+	diff, secrets := git.RedactSecrets(cfg, git.FilterGeneratedDiff(cfg, diff))
+	ui.ReportSecrets(secrets, cfg.BlockOnSecrets)
+
+	messages, err := buildBranchMessages(cfg, description, diff, noLLM)
+	if err != nil {
+		exitOnGenerationError(err)
+	}
+
+	if showPrompt || noLLM {
+		ui.ShowPrompt(messages)
+		if noLLM {
+			return
+		}
+	}
+
 	// Generate branch name - with spinner
 	branchName, err := ui.WithSpinnerResult("Generating branch name with LLM...", func() (string, error) {
-		return generateBranchNameWithDiff(cfg, description, diff)
+		return generateBranchName(cfg, messages)
 	})
 	if err != nil {
-		if errors.Is(err, config.ErrLLMNotConfigured) {
-			ui.PrintError("LLM endpoint or API key not configured. Please run 'git ai config' to set up.")
-			os.Exit(1)
+		exitOnGenerationError(err)
+	}
+
+	ui.SetResult("model", cfg.Model)
+	ui.SetResult("provider", cfg.Provider)
+
+	if dryRun {
+		ui.SetResult("branch", branchName)
+		if !ui.IsJSONOutput() {
+			fmt.Println(branchName)
 		}
-		ui.PrintErrorf("Failed to generate branch name: %v", err)
-		os.Exit(1)
+		return
 	}
 
 	// If auto-approve flag is not set, ask user to confirm or edit
@@ -49,49 +70,69 @@ func executeBranch(description, diff string) {
 				logger.Fatal("Error prompting for input: %v", err)
 			}
 			if branchName == "" {
-				logger.Error("Branch name cannot be empty.")
-				os.Exit(1)
+				ui.PrintFatal("Branch name cannot be empty.")
 			}
 			proceed = true
 		case "Print name only":
 			ui.PrintMessage(branchName)
-			os.Exit(0)
+			ui.Exit(0)
 		case "Cancel":
 			ui.PrintMessage("Branch creation cancelled.")
-			os.Exit(0)
+			ui.Exit(0)
 		}
 	} else {
 		proceed = true
 	}
 
 	if proceed {
+		ui.SetResult("branch", branchName)
+
 		// Create the branch
 		err = git.CreateBranch(branchName)
 		if err != nil {
 			logger.Fatal("Failed to create branch: %v", err)
 		}
 
+		ui.SetResult("created", true)
 		ui.PrintMessagef("Branch '%s' created successfully!", branchName)
 	}
 }
 
-// generateBranchNameWithDiff generates a branch name based on user input, diff, and existing branches
-func generateBranchNameWithDiff(cfg config.Config, request, diff string) (string, error) {
-	if cfg.APIKey == "" {
-		return "", config.ErrLLMNotConfigured
-	}
-
-	client, err := llm.NewClientWithProvider(cfg.Provider, cfg.Endpoint, cfg.APIKey)
-	if err != nil {
-		return "", fmt.Errorf("failed to create LLM client: %w", err)
+// buildBranchMessages builds the prompt for generating a branch name from user input, the diff
+// and existing branches. In offline mode large diffs are compacted but not summarized and no API key is required.
+func buildBranchMessages(cfg config.Config, request, diff string, offline bool) ([]llm.Message, error) {
+	if cfg.APIKey == "" && !offline {
+		return nil, config.ErrLLMNotConfigured
 	}
 
 	// Process diff with summarization if needed (32k token limit)
-	processedDiff := ""
+	processedDiff := diff
 	isSummarized := false
-	if diff != "" {
+	if diff != "" && offline {
+		// Compacting needs no LLM, so show the diff as it would be sent
+		compacted, fits := git.CompactDiff(diff, 32000)
+		if fits {
+			processedDiff = compacted
+		} else {
+			logger.Warn("The diff is about %d tokens and would be summarized before being sent", git.EstimateTokens(compacted))
+		}
+	} else if diff != "" {
+		summarize := func(progress git.SummaryProgress) (string, error) {
+			processed, summarized, err := git.ProcessDiffWithSummarization(cfg, diff, 32000, progress)
+			isSummarized = summarized
+			return processed, err
+		}
+
 		var err error
-		processedDiff, isSummarized, err = git.ProcessDiffWithSummarization(cfg, diff, 32000)
+		if git.EstimateTokens(diff) > 32000 {
+			processedDiff, err = ui.WithProgressSpinner("Summarizing large diff with LLM...", func(update func(string)) (string, error) {
+				return summarize(func(status string) {
+					update(status + " with LLM...")
+				})
+			})
+		} else {
+			processedDiff, err = summarize(nil)
+		}
 		if err != nil {
 			logger.Warn("Failed to process diff with summarization, using original: %v", err)
 			processedDiff = diff
@@ -99,6 +140,8 @@ func generateBranchNameWithDiff(cfg config.Config, request, diff string) (string
 		}
 	}
 
+	ui.SetResult("summarized", isSummarized)
+
 	// Get lists of existing branches
 	localBranches, err := git.GetLocalBranches()
 	if err != nil {
@@ -115,16 +158,15 @@ func generateBranchNameWithDiff(cfg config.Config, request, diff string) (string
 	// Get system and user prompts
 	systemPrompt, err := llm.GetBranchSystemPrompt(isSummarized)
 	if err != nil {
-		return "", fmt.Errorf("failed to build system prompt: %w", err)
+		return nil, fmt.Errorf("failed to build system prompt: %w", err)
 	}
 
 	userPrompt, err := llm.GetBranchUserPrompt(request, localBranches, remoteBranches, processedDiff)
 	if err != nil {
-		return "", fmt.Errorf("failed to build user prompt: %w", err)
+		return nil, fmt.Errorf("failed to build user prompt: %w", err)
 	}
 
-	// Call the LLM API
-	messages := []llm.Message{
+	return []llm.Message{
 		{
 			Role:    "system",
 			Content: systemPrompt,
@@ -133,8 +175,17 @@ func generateBranchNameWithDiff(cfg config.Config, request, diff string) (string
 			Role:    "user",
 			Content: userPrompt,
 		},
+	}, nil
+}
+
+// generateBranchName asks the LLM for a branch name using the prepared prompt
+func generateBranchName(cfg config.Config, messages []llm.Message) (string, error) {
+	client, err := llm.NewClientWithProvider(cfg.Provider, cfg.Endpoint, cfg.APIKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to create LLM client: %w", err)
 	}
 
+	// Call the LLM API
 	response, err := client.ChatCompletion(cfg.Model, messages)
 	if err != nil {
 		return "", fmt.Errorf("failed to get completion: %w", err)
@@ -149,6 +200,14 @@ func generateBranchNameWithDiff(cfg config.Config, request, diff string) (string
 	return branchName, nil
 }
 
+// exitOnGenerationError reports a failed branch name generation and exits
+func exitOnGenerationError(err error) {
+	if errors.Is(err, config.ErrLLMNotConfigured) {
+		ui.PrintFatal("LLM endpoint or API key not configured. Please run 'git ai config' to set up.")
+	}
+	ui.PrintFatalf("Failed to generate branch name: %v", err)
+}
+
 // sanitizeBranchName ensures the branch name follows Git conventions
 func sanitizeBranchName(name string) string {
 	// Replace spaces with hyphens
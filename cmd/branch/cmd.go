@@ -5,12 +5,14 @@ import (
 	"github.com/recrsn/git-ai/pkg/logger"
 	"github.com/recrsn/git-ai/pkg/ui"
 	"github.com/spf13/cobra"
-	"os"
 )
 
 var (
 	autoApprove bool
 	description string
+	dryRun      bool
+	showPrompt  bool
+	noLLM       bool
 )
 
 // Cmd represents the branch command
@@ -39,8 +41,7 @@ var Cmd = &cobra.Command{
 				logger.Fatal("Error prompting for description: %v", err)
 			}
 			if description == "" {
-				ui.PrintError("Description cannot be empty.")
-				os.Exit(1)
+				ui.PrintFatal("Description cannot be empty.")
 			}
 		}
 
@@ -51,4 +52,7 @@ var Cmd = &cobra.Command{
 func init() {
 	Cmd.Flags().BoolVar(&autoApprove, "auto", false, "Automatically approve the generated branch name without prompting")
 	Cmd.Flags().StringVarP(&description, "description", "d", "", "Brief description of the branch purpose")
+	Cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the generated branch name without creating the branch")
+	Cmd.Flags().BoolVar(&showPrompt, "show-prompt", false, "Print the prompt sent to the LLM and its estimated token count")
+	Cmd.Flags().BoolVar(&noLLM, "no-llm", false, "Don't call the LLM, only print the prompt (implies --show-prompt)")
 }
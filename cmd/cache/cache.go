@@ -0,0 +1,23 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/recrsn/git-ai/pkg/git"
+	"github.com/recrsn/git-ai/pkg/logger"
+	"github.com/recrsn/git-ai/pkg/ui"
+)
+
+func executeClear() {
+	removed, err := git.ClearSummaryCache()
+	if err != nil {
+		logger.Fatal("Failed to clear summary cache: %v", err)
+	}
+
+	ui.SetResult("removed", removed)
+	if removed == 0 {
+		ui.PrintMessage("The summary cache is already empty.")
+		return
+	}
+	ui.PrintSuccess(fmt.Sprintf("Removed %d cached summaries", removed))
+}
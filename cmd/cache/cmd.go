@@ -0,0 +1,28 @@
+package cache
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Cmd represents the cache command
+var Cmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the cache of diff summaries",
+	Long: `Large diffs are summarized file by file before a message is generated. The summaries
+are cached in the repository's git directory, so that generating another message for the
+same changes doesn't summarize them again.`,
+}
+
+var clearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached diff summaries",
+	Long:  `Removes the cached diff summaries of the current repository.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		executeClear()
+	},
+}
+
+func init() {
+	Cmd.AddCommand(clearCmd)
+}
@@ -2,7 +2,8 @@ package commit
 
 import (
 	"errors"
-	"os"
+	"fmt"
+	"strings"
 
 	"github.com/recrsn/git-ai/pkg/config"
 	"github.com/recrsn/git-ai/pkg/git"
@@ -11,12 +12,20 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// defaultAlternatives is the number of messages requested by "Show alternatives"
+// unless --candidates asks for more
+const defaultAlternatives = 3
+
 var (
 	autoApprove             bool
 	conventionalCommits     bool
 	noConventionalCommits   bool
 	commitsWithDescriptions bool
 	amendCommit             bool
+	candidates              int
+	dryRun                  bool
+	showPrompt              bool
+	noLLM                   bool
 )
 
 // Cmd represents the commit command
@@ -35,6 +44,10 @@ func init() {
 	Cmd.Flags().BoolVar(&noConventionalCommits, "no-conventional", false, "Don't use conventional commit format")
 	Cmd.Flags().BoolVar(&commitsWithDescriptions, "with-descriptions", false, "Generate commit messages with detailed descriptions")
 	Cmd.Flags().BoolVarP(&amendCommit, "amend", "a", false, "Amend the previous commit instead of creating a new one")
+	Cmd.Flags().IntVar(&candidates, "candidates", 1, "Generate this many alternative messages to choose from")
+	Cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the generated message without committing")
+	Cmd.Flags().BoolVar(&showPrompt, "show-prompt", false, "Print the prompt sent to the LLM and its estimated token count")
+	Cmd.Flags().BoolVar(&noLLM, "no-llm", false, "Don't call the LLM, only print the prompt (implies --show-prompt)")
 }
 
 func executeCommit() {
@@ -45,52 +58,114 @@ func executeCommit() {
 		if amendCommit {
 			ui.PrintMessage("No staged changes found. Will amend the previous commit message only.")
 		} else {
-			ui.PrintErrorf("No staged changes found. Please stage your changes with 'git add' first.")
-			os.Exit(1)
+			ui.PrintFatalf("No staged changes found. Please stage your changes with 'git add' first.")
 		}
 	}
 
 	// If the --with-descriptions flag wasn't explicitly set, check git config
 	if !commitsWithDescriptions {
-		value, err := git.GetConfig("git-ai.commitsWithDescriptions")
-		if err == nil && value == "true" {
-			commitsWithDescriptions = true
-		}
+		commitsWithDescriptions = DescriptionsPreference()
 	}
 
 	// Get the staged changes diff, filtering out generated files
-	diff := git.GetStagedDiffFiltered()
+	diff := git.GetStagedDiffFiltered(cfg)
 	if diff == "" {
 		logger.Fatal("Could not retrieve diff of staged changes.")
 	}
 
+	// Mask secrets before anything is sent to the LLM
+	diff, secrets := git.RedactSecrets(cfg, diff)
+	ui.ReportSecrets(secrets, cfg.BlockOnSecrets)
+
 	// Get recent commit history
 	recentCommits := git.GetRecentCommits()
 
 	// Determine whether to use conventional commits format
 	useConventionalCommits := shouldUseConventionalCommits()
 
+	// Prepare the prompts once so that regenerating doesn't summarize the diff again
+	prepareSession := func(progress git.SummaryProgress) (*messageSession, error) {
+		return newMessageSession(cfg, diff, git.GetChangedFiles(cfg), recentCommits, useConventionalCommits, commitsWithDescriptions, noLLM, progress)
+	}
+	var session *messageSession
+	var err error
+	if !noLLM && git.EstimateTokens(diff) > 32000 {
+		session, err = ui.WithProgressSpinner("Summarizing large diff with LLM...", func(update func(string)) (*messageSession, error) {
+			return prepareSession(func(status string) {
+				update(status + " with LLM...")
+			})
+		})
+	} else {
+		session, err = prepareSession(nil)
+	}
+	if err != nil {
+		exitOnGenerationError(err)
+	}
+
+	if showPrompt || noLLM {
+		ui.ShowPrompt(session.messages)
+		if noLLM {
+			return
+		}
+	}
+
+	spinnerText := "Generating commit message with LLM..."
+	if candidates > 1 {
+		spinnerText = fmt.Sprintf("Generating %d commit messages with LLM...", candidates)
+	}
+
 	// Generate commit message based on staged changes and history - with spinner
-	message, err := ui.WithSpinnerResult("Generating commit message with LLM...", func() (string, error) {
-		return GenerateCommitMessage(cfg, diff, recentCommits, useConventionalCommits, commitsWithDescriptions)
+	messages, err := ui.WithSpinnerResult(spinnerText, func() ([]string, error) {
+		if candidates > 1 {
+			return session.Candidates(candidates)
+		}
+		message, err := session.Generate()
+		return []string{message}, err
 	})
 	if err != nil {
-		if errors.Is(err, config.ErrLLMNotConfigured) {
-			ui.PrintError("LLM endpoint or API key not configured. Please run 'git ai config' to set up.")
-			os.Exit(1)
+		exitOnGenerationError(err)
+	}
+
+	ui.SetResult("model", cfg.Model)
+	ui.SetResult("provider", cfg.Provider)
+	ui.SetResult("summarized", session.summarized)
+
+	message := messages[0]
+	if dryRun {
+		ui.SetMessageResult(message)
+		if len(messages) > 1 {
+			ui.SetResult("candidates", messages)
+		}
+		if !ui.IsJSONOutput() {
+			fmt.Println(strings.Join(messages, "\n---\n"))
+		}
+		return
+	}
+
+	if len(messages) > 1 && !autoApprove {
+		message, err = ui.PromptForCandidate(messages)
+		if err != nil {
+			logger.Fatal("Error selecting commit message: %v", err)
 		}
-		logger.Fatal("Failed to generate commit message: %v", err)
 	}
 
 	// If auto-approve flag is not set, ask user to confirm or edit
 	var proceed bool
 	if !autoApprove {
-		message, proceed = ui.PromptForConfirmation(message)
+		message, proceed = ui.PromptForConfirmationWithOptions(message, ui.ConfirmationOptions{
+			Refine:     session.Refine,
+			Regenerate: session.Generate,
+			Alternatives: func() ([]string, error) {
+				return session.Candidates(max(candidates, defaultAlternatives))
+			},
+		})
 		if !proceed {
-			os.Exit(0)
+			ui.Exit(0)
 		}
 	}
 
+	ui.SetMessageResult(message)
+
 	// If the user explicitly chose a commit format, save the preference
 	if conventionalCommits || noConventionalCommits {
 		saveCommitFormatPreference(conventionalCommits)
@@ -114,6 +189,7 @@ func executeCommit() {
 	} else {
 		if commitHash != "" {
 			logger.Debug("Commit created: %s", commitHash)
+			ui.SetResult("commit", commitHash)
 		}
 	}
 
@@ -124,6 +200,14 @@ func executeCommit() {
 	}
 }
 
+// exitOnGenerationError reports a failed message generation and exits
+func exitOnGenerationError(err error) {
+	if errors.Is(err, config.ErrLLMNotConfigured) {
+		ui.PrintFatal("LLM endpoint or API key not configured. Please run 'git ai config' to set up.")
+	}
+	logger.Fatal("Failed to generate commit message: %v", err)
+}
+
 // shouldUseConventionalCommits determines whether to use conventional commit format
 // based on command-line flags, git config, and repository history
 func shouldUseConventionalCommits() bool {
@@ -135,6 +219,12 @@ func shouldUseConventionalCommits() bool {
 		return false
 	}
 
+	return ConventionalCommitsPreference()
+}
+
+// ConventionalCommitsPreference determines whether to use conventional commit format
+// based on the saved git config preference and repository history
+func ConventionalCommitsPreference() bool {
 	// Check git config for saved preference
 	configKey := "git-ai.conventionalCommits"
 	value, err := git.GetConfig(configKey)
@@ -147,6 +237,12 @@ func shouldUseConventionalCommits() bool {
 	return git.UsesConventionalCommits()
 }
 
+// DescriptionsPreference reports whether the saved git config asks for commit messages with descriptions
+func DescriptionsPreference() bool {
+	value, err := git.GetConfig("git-ai.commitsWithDescriptions")
+	return err == nil && value == "true"
+}
+
 // saveCommitFormatPreference saves the user's preference for commit format to git config
 func saveCommitFormatPreference(useConventional bool) {
 	value := "false"
@@ -12,53 +12,93 @@ import (
 
 // GenerateCommitMessage generates a commit message based on staged changes and commit history
 func GenerateCommitMessage(cfg config.Config, diff, recentCommits string, useConventionalCommits bool, commitsWithDescriptions bool) (string, error) {
+	return GenerateMessageForDiff(cfg, diff, git.GetChangedFiles(cfg), recentCommits, useConventionalCommits, commitsWithDescriptions)
+}
+
+// GenerateMessageForDiff generates a commit message for an arbitrary diff and its list of changed files
+func GenerateMessageForDiff(cfg config.Config, diff, changedFiles, recentCommits string, useConventionalCommits bool, commitsWithDescriptions bool) (string, error) {
+	session, err := newMessageSession(cfg, diff, changedFiles, recentCommits, useConventionalCommits, commitsWithDescriptions, false, nil)
+	if err != nil {
+		return "", err
+	}
+	return session.Generate()
+}
+
+// messageSession holds the conversation used to generate a commit message, so that
+// further messages can be requested without rebuilding the prompts or summarizing the diff again
+type messageSession struct {
+	model      string
+	client     *llm.Client
+	messages   []llm.Message
+	summarized bool
+}
+
+// newMessageSession prepares the prompts for generating commit messages for a diff.
+// In offline mode no requests are made: large diffs are compacted but not summarized and no API key is required.
+// progress, if not nil, is told how far summarizing a large diff has come.
+func newMessageSession(cfg config.Config, diff, changedFiles, recentCommits string, useConventionalCommits bool, commitsWithDescriptions bool, offline bool, progress git.SummaryProgress) (*messageSession, error) {
 	// Use the LLM for commit message generation
-	if cfg.APIKey == "" {
-		return "", config.ErrLLMNotConfigured
+	if cfg.APIKey == "" && !offline {
+		return nil, config.ErrLLMNotConfigured
 	}
 
 	logger.Debug("Using provider: %s, endpoint: %s, model: %s", cfg.Provider, cfg.Endpoint, cfg.Model)
 
 	client, err := llm.NewClientWithProvider(cfg.Provider, cfg.Endpoint, cfg.APIKey)
 	if err != nil {
-		return "", fmt.Errorf("failed to create LLM client: %w", err)
+		return nil, fmt.Errorf("failed to create LLM client: %w", err)
 	}
 
-	// Process diff with summarization if needed (32k token limit)
-	processedDiff, isSummarized, err := git.ProcessDiffWithSummarization(cfg, diff, 32000)
-	if err != nil {
-		logger.Warn("Failed to process diff with summarization, using original: %v", err)
-		processedDiff = diff
-		isSummarized = false
+	processedDiff, isSummarized := diff, false
+	if offline {
+		// Compacting needs no LLM, so show the diff as it would be sent
+		compacted, fits := git.CompactDiff(diff, 32000)
+		if fits {
+			processedDiff = compacted
+		} else {
+			logger.Warn("The diff is about %d tokens and would be summarized before being sent", git.EstimateTokens(compacted))
+		}
+	} else {
+		// Process diff with summarization if needed (32k token limit)
+		processedDiff, isSummarized, err = git.ProcessDiffWithSummarization(cfg, diff, 32000, progress)
+		if err != nil {
+			logger.Warn("Failed to process diff with summarization, using original: %v", err)
+			processedDiff = diff
+			isSummarized = false
+		}
 	}
 
-	// Get the changed files
-	changedFiles := git.GetChangedFiles()
-
 	// Get system and user prompts
 	systemPrompt, err := llm.GetSystemPrompt(useConventionalCommits, commitsWithDescriptions, isSummarized)
 	if err != nil {
-		return "", fmt.Errorf("failed to build system prompt: %w", err)
+		return nil, fmt.Errorf("failed to build system prompt: %w", err)
 	}
 
 	userPrompt, err := llm.GetUserPrompt(processedDiff, changedFiles, recentCommits)
 	if err != nil {
-		return "", fmt.Errorf("failed to build user prompt: %w", err)
+		return nil, fmt.Errorf("failed to build user prompt: %w", err)
 	}
 
-	// Call the LLM API
-	messages := []llm.Message{
-		{
-			Role:    "system",
-			Content: systemPrompt,
+	return &messageSession{
+		model:      cfg.Model,
+		client:     client,
+		summarized: isSummarized,
+		messages: []llm.Message{
+			{
+				Role:    "system",
+				Content: systemPrompt,
+			},
+			{
+				Role:    "user",
+				Content: userPrompt,
+			},
 		},
-		{
-			Role:    "user",
-			Content: userPrompt,
-		},
-	}
+	}, nil
+}
 
-	response, err := client.ChatCompletion(cfg.Model, messages)
+// Generate requests a commit message for the session's conversation
+func (s *messageSession) Generate() (string, error) {
+	response, err := s.client.ChatCompletion(s.model, s.messages)
 	if err != nil {
 		return "", fmt.Errorf("failed to get completion: %w", err)
 	}
@@ -66,3 +106,41 @@ func GenerateCommitMessage(cfg config.Config, diff, recentCommits string, useCon
 	// Clean up the response
 	return strings.TrimSpace(response), nil
 }
+
+// Refine asks for a revised version of message following the user's instruction. The previous
+// answer and the instruction stay in the conversation, so later refinements build on earlier ones.
+func (s *messageSession) Refine(message, instruction string) (string, error) {
+	conversation := len(s.messages)
+	s.messages = append(s.messages,
+		llm.Message{
+			Role:    "assistant",
+			Content: message,
+		},
+		llm.Message{
+			Role:    "user",
+			Content: fmt.Sprintf("Revise the commit message: %s\n\nRespond ONLY with the revised commit message.", instruction),
+		},
+	)
+
+	refined, err := s.Generate()
+	if err != nil {
+		// Forget the failed turn so that it can be retried
+		s.messages = s.messages[:conversation]
+		return "", err
+	}
+	return refined, nil
+}
+
+// Candidates requests n alternative commit messages and returns the distinct ones
+func (s *messageSession) Candidates(n int) ([]string, error) {
+	responses, err := s.client.ChatCompletions(s.model, s.messages, n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get completions: %w", err)
+	}
+
+	messages := llm.Deduplicate(responses)
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no commit messages returned")
+	}
+	return messages, nil
+}
@@ -0,0 +1,32 @@
+package fixup
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	autoApprove bool
+	useLLM      bool
+)
+
+// Cmd represents the fixup command
+var Cmd = &cobra.Command{
+	Use:   "fixup [base]",
+	Short: "Create fixup! commits for staged changes",
+	Long: `Finds the commit on the current branch (since it diverged from base) that each staged
+hunk most likely belongs to using git blame, optionally confirmed by the LLM, and creates
+fixup! commits so that 'git rebase -i --autosquash' can fold them in.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		base := ""
+		if len(args) > 0 {
+			base = args[0]
+		}
+		executeFixup(base)
+	},
+}
+
+func init() {
+	Cmd.Flags().BoolVar(&autoApprove, "auto", false, "Automatically create the fixup commits without prompting")
+	Cmd.Flags().BoolVar(&useLLM, "llm", false, "Ask the LLM to confirm the commit each hunk belongs to")
+}
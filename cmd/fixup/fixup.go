@@ -0,0 +1,248 @@
+package fixup
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/git"
+	"github.com/recrsn/git-ai/pkg/logger"
+	"github.com/recrsn/git-ai/pkg/ui"
+)
+
+// branchCommit is a commit on the current branch that hunks can be folded into
+type branchCommit struct {
+	hash    string
+	subject string
+}
+
+// shortHash abbreviates the commit hash for display
+func (c branchCommit) shortHash() string {
+	if len(c.hash) > 7 {
+		return c.hash[:7]
+	}
+	return c.hash
+}
+
+func executeFixup(base string) {
+	if !git.HasStagedChanges() {
+		ui.PrintFatalf("No staged changes found. Please stage your changes with 'git add' first.")
+	}
+
+	if base == "" {
+		var err error
+		base, err = git.GetDefaultBaseBranch()
+		if err != nil {
+			ui.PrintFatalf("%v", err)
+		}
+	}
+
+	mergeBase, err := git.GetMergeBase(base, "HEAD")
+	if err != nil {
+		ui.PrintFatalf("Could not find a common ancestor with %s: %v", base, err)
+	}
+
+	hashes, err := git.GetCommitsInRange(mergeBase, "HEAD")
+	if err != nil {
+		logger.Fatal("Failed to list branch commits: %v", err)
+	}
+	if len(hashes) == 0 {
+		ui.PrintMessagef("No commits on this branch since %s, nothing to fix up.", base)
+		return
+	}
+
+	var commits []branchCommit
+	for _, hash := range hashes {
+		info, err := git.GetCommitInfo(hash)
+		if err != nil {
+			logger.Fatal("Failed to read commit %s: %v", hash, err)
+		}
+		subject, _, _ := strings.Cut(info.Message, "\n")
+		commits = append(commits, branchCommit{hash: hash, subject: subject})
+	}
+
+	hunks := git.ParseDiffHunks(git.GetStagedPatch())
+	if len(hunks) == 0 {
+		logger.Fatal("Could not retrieve diff of staged changes.")
+	}
+
+	targets := make(map[int]string)
+	for _, hunk := range hunks {
+		targets[hunk.ID] = suggestTarget(hunk, commits)
+	}
+
+	if useLLM {
+		cfg := config.LoadConfigOrFatal()
+
+		// Mask excluded files and secrets in the copy sent to the LLM; the original hunks are applied later
+		scanner := git.NewSecretScanner(cfg)
+		promptHunks, secrets := scanner.RedactHunks(git.MaskExcludedHunks(cfg, hunks))
+		promptCommits := make([]branchCommit, len(commits))
+		for i, c := range commits {
+			subject, subjectSecrets := scanner.RedactText("commit "+c.shortHash(), 0, c.subject)
+			promptCommits[i] = branchCommit{hash: c.hash, subject: subject}
+			secrets = append(secrets, subjectSecrets...)
+		}
+		ui.ReportSecrets(secrets, cfg.BlockOnSecrets)
+
+		confirmed, err := ui.WithSpinnerResult("Confirming fixup targets with LLM...", func() (map[int]string, error) {
+			return confirmTargets(cfg, promptHunks, targets, promptCommits)
+		})
+		if err != nil {
+			if errors.Is(err, config.ErrLLMNotConfigured) {
+				ui.PrintFatal("LLM endpoint or API key not configured. Please run 'git ai config' to set up.")
+			}
+			logger.Warn("Failed to confirm targets with LLM, using blame results: %v", err)
+		}
+		for id, hash := range confirmed {
+			targets[id] = hash
+		}
+	}
+
+	groups := groupByTarget(hunks, targets, commits)
+	displayPlan(groups, hunks, targets)
+
+	if len(groups) == 0 {
+		ui.PrintMessage("Could not match any staged hunk to a commit on this branch.")
+		return
+	}
+
+	if !autoApprove {
+		options := []string{"Create fixup commits", "Cancel"}
+		selectedOption, err := ui.PromptForSelection(options, "Create fixup commits", "What would you like to do?")
+		if err != nil {
+			logger.Fatal("Error prompting for selection: %v", err)
+		}
+		if selectedOption != "Create fixup commits" {
+			ui.PrintMessage("Fixup cancelled.")
+			ui.Exit(0)
+		}
+	}
+
+	applyFixups(groups)
+
+	ui.PrintSuccess(fmt.Sprintf("Created %d fixup commits successfully!", len(groups)))
+	ui.PrintMessagef("Run 'git rebase -i --autosquash %s' to fold them in.", shortRev(mergeBase))
+}
+
+// suggestTarget blames the lines a hunk touches and returns the branch commit
+// responsible for most of them, or an empty string if none is
+func suggestTarget(hunk git.DiffHunk, commits []branchCommit) string {
+	oldPath := hunk.OldPath()
+	ranges := hunk.OldLineRanges()
+	if oldPath == "" || len(ranges) == 0 {
+		return ""
+	}
+
+	blamed, err := git.BlameLines("HEAD", oldPath, ranges)
+	if err != nil {
+		return ""
+	}
+
+	counts := make(map[string]int)
+	for _, hash := range blamed {
+		counts[hash]++
+	}
+
+	// Prefer the most recent commit on ties
+	best := ""
+	bestCount := 0
+	for _, c := range commits {
+		if counts[c.hash] > 0 && counts[c.hash] >= bestCount {
+			best = c.hash
+			bestCount = counts[c.hash]
+		}
+	}
+	return best
+}
+
+// fixupGroup is a set of hunks to commit as a fixup of a single commit
+type fixupGroup struct {
+	target branchCommit
+	hunks  []git.DiffHunk
+}
+
+// groupByTarget groups assigned hunks by their target commit, oldest commit first
+func groupByTarget(hunks []git.DiffHunk, targets map[int]string, commits []branchCommit) []fixupGroup {
+	var groups []fixupGroup
+	for _, c := range commits {
+		group := fixupGroup{target: c}
+		for _, hunk := range hunks {
+			if targets[hunk.ID] == c.hash {
+				group.hunks = append(group.hunks, hunk)
+			}
+		}
+		if len(group.hunks) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// displayPlan shows which hunks go into which fixup commit and which stay staged
+func displayPlan(groups []fixupGroup, hunks []git.DiffHunk, targets map[int]string) {
+	for _, group := range groups {
+		var content strings.Builder
+		for i, hunk := range group.hunks {
+			if i > 0 {
+				content.WriteString("\n")
+			}
+			content.WriteString(fmt.Sprintf("• %s (hunk %d)", hunk.Path, hunk.ID))
+		}
+		ui.DisplayBox(fmt.Sprintf("fixup! %s (%s)", group.target.subject, group.target.shortHash()), content.String())
+	}
+
+	var unassigned []string
+	for _, hunk := range hunks {
+		if targets[hunk.ID] == "" {
+			unassigned = append(unassigned, fmt.Sprintf("• %s (hunk %d)", hunk.Path, hunk.ID))
+		}
+	}
+	if len(unassigned) > 0 {
+		ui.DisplayBox("Left staged", strings.Join(unassigned, "\n"))
+	}
+}
+
+// applyFixups commits each group as a fixup of its target. Hunks without a target
+// remain staged afterwards.
+func applyFixups(groups []fixupGroup) {
+	originalTree, err := git.WriteIndexTree()
+	if err != nil {
+		logger.Fatal("Failed to save the current index: %v", err)
+	}
+
+	// The original tree contains all staged changes; after the fixup commits it
+	// leaves exactly the remaining changes staged
+	restoreIndex := func() {
+		if err := git.ReadTreeIntoIndex(originalTree); err != nil {
+			logger.Error("Failed to restore the index, your staged changes are saved in tree %s: %v", originalTree, err)
+		}
+	}
+	defer restoreIndex()
+
+	if err := git.ResetIndex(); err != nil {
+		restoreIndex()
+		logger.Fatal("Failed to reset the index: %v", err)
+	}
+
+	for _, group := range groups {
+		if err := git.ApplyPatchToIndex(git.BuildPatch(group.hunks)); err != nil {
+			restoreIndex()
+			ui.PrintFatalf("Failed to stage hunks for %s: %v", group.target.shortHash(), err)
+		}
+
+		if err := git.CreateFixupCommit(group.target.hash); err != nil {
+			restoreIndex()
+			ui.PrintFatalf("Failed to create fixup commit for %s: %v", group.target.shortHash(), err)
+		}
+	}
+}
+
+// shortRev abbreviates a commit hash for display
+func shortRev(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -0,0 +1,116 @@
+package fixup
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/git"
+	"github.com/recrsn/git-ai/pkg/llm"
+	"github.com/recrsn/git-ai/pkg/logger"
+)
+
+// maxHunkPromptLength limits how much of a single hunk is sent to the LLM
+const maxHunkPromptLength = 2000
+
+// fixupAssignment is the LLM's choice of target commit for a single hunk
+type fixupAssignment struct {
+	Hunk   int     `json:"hunk"`
+	Commit *string `json:"commit"`
+}
+
+// confirmTargets asks the LLM to confirm or correct the blame-based targets. The returned
+// map has an entry for every hunk the LLM answered for, with an empty hash meaning no target.
+func confirmTargets(cfg config.Config, hunks []git.DiffHunk, targets map[int]string, commits []branchCommit) (map[int]string, error) {
+	if cfg.APIKey == "" {
+		return nil, config.ErrLLMNotConfigured
+	}
+
+	client, err := llm.NewClientWithProvider(cfg.Provider, cfg.Endpoint, cfg.APIKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("# Branch commits (oldest first):\n")
+	for _, c := range commits {
+		prompt.WriteString(fmt.Sprintf("- %s %s\n", c.shortHash(), c.subject))
+	}
+
+	prompt.WriteString("\n# Staged hunks:\n")
+	byHash := make(map[string]branchCommit, len(commits))
+	for _, c := range commits {
+		byHash[c.hash] = c
+	}
+	for _, hunk := range hunks {
+		suggestion := "none"
+		if target := targets[hunk.ID]; target != "" {
+			suggestion = byHash[target].shortHash()
+		}
+
+		content := hunk.Content
+		if content == "" {
+			content = hunk.Header
+		}
+		if len(content) > maxHunkPromptLength {
+			content = content[:maxHunkPromptLength] + "\n... (truncated)\n"
+		}
+
+		prompt.WriteString(fmt.Sprintf("\n## Hunk %d: %s (blame suggests: %s)\n```diff\n%s```\n", hunk.ID, hunk.Path, suggestion, content))
+	}
+
+	messages := []llm.Message{
+		{
+			Role:    "system",
+			Content: llm.GetFixupSystemPrompt(),
+		},
+		{
+			Role:    "user",
+			Content: prompt.String(),
+		},
+	}
+
+	response, err := client.ChatCompletion(cfg.Model, messages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get completion: %w", err)
+	}
+
+	var result struct {
+		Assignments []fixupAssignment `json:"assignments"`
+	}
+	if err := json.Unmarshal([]byte(llm.StripCodeFence(response)), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse assignments: %w, response: %s", err, response)
+	}
+
+	confirmed := make(map[int]string)
+	for _, assignment := range result.Assignments {
+		if assignment.Commit == nil || *assignment.Commit == "" {
+			confirmed[assignment.Hunk] = ""
+			continue
+		}
+
+		hash := findCommit(commits, *assignment.Commit)
+		if hash == "" {
+			logger.Warn("Ignoring unknown commit %s suggested for hunk %d", *assignment.Commit, assignment.Hunk)
+			continue
+		}
+		confirmed[assignment.Hunk] = hash
+	}
+
+	return confirmed, nil
+}
+
+// findCommit returns the full hash of the branch commit matching a (possibly abbreviated) hash
+func findCommit(commits []branchCommit, prefix string) string {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if len(prefix) < 4 {
+		return ""
+	}
+	for _, c := range commits {
+		if strings.HasPrefix(c.hash, prefix) {
+			return c.hash
+		}
+	}
+	return ""
+}
@@ -0,0 +1,54 @@
+package hook
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Cmd represents the hook command
+var Cmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manage the prepare-commit-msg hook",
+	Long: `Installs or removes a prepare-commit-msg hook so that a plain 'git commit'
+opens the editor with an AI-generated commit message already filled in.`,
+}
+
+var installCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the prepare-commit-msg hook",
+	Long: `Writes a prepare-commit-msg hook into the repository's hooks directory, respecting
+core.hooksPath. An existing hook is kept and run before git-ai.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		executeInstall()
+	},
+}
+
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the prepare-commit-msg hook",
+	Long:  `Removes the git-ai prepare-commit-msg hook and restores any hook it was chained to.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		executeUninstall()
+	},
+}
+
+var prepareCommitMsgCmd = &cobra.Command{
+	Use:    "prepare-commit-msg <file> [source] [sha]",
+	Short:  "Entry point called by the prepare-commit-msg hook",
+	Hidden: true,
+	Args:   cobra.RangeArgs(1, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		source := ""
+		if len(args) > 1 {
+			source = args[1]
+		}
+		executePrepareCommitMsg(args[0], source)
+	},
+}
+
+func init() {
+	Cmd.AddCommand(installCmd)
+	Cmd.AddCommand(uninstallCmd)
+	Cmd.AddCommand(prepareCommitMsgCmd)
+}
@@ -0,0 +1,201 @@
+package hook
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/recrsn/git-ai/cmd/commit"
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/git"
+	"github.com/recrsn/git-ai/pkg/logger"
+	"github.com/recrsn/git-ai/pkg/ui"
+)
+
+const (
+	hookName = "prepare-commit-msg"
+
+	// chainedHookSuffix is appended to a pre-existing hook that git-ai runs before itself
+	chainedHookSuffix = ".pre-git-ai"
+
+	// hookMarker identifies hooks written by git-ai
+	hookMarker = "# git-ai prepare-commit-msg hook"
+)
+
+const hookScript = `#!/bin/sh
+` + hookMarker + `
+# Installed by 'git ai hook install'. Remove with 'git ai hook uninstall'.
+
+chained="$(dirname "$0")/` + hookName + chainedHookSuffix + `"
+if [ -x "$chained" ]; then
+	"$chained" "$@" || exit $?
+fi
+
+if command -v git-ai >/dev/null 2>&1; then
+	git-ai hook ` + hookName + ` "$@" </dev/null || true
+fi
+`
+
+func executeInstall() {
+	hooksDir, err := git.GetHooksDir()
+	if err != nil {
+		logger.Fatal("Failed to locate hooks directory: %v", err)
+	}
+
+	hookPath := filepath.Join(hooksDir, hookName)
+	chainedPath := hookPath + chainedHookSuffix
+
+	existing, err := os.ReadFile(hookPath)
+	if err == nil {
+		if strings.Contains(string(existing), hookMarker) {
+			ui.PrintMessagef("The git-ai %s hook is already installed in %s", hookName, hooksDir)
+			return
+		}
+
+		// Keep the existing hook and run it before git-ai
+		if _, err := os.Stat(chainedPath); err == nil {
+			ui.PrintFatalf("Cannot chain existing hook: %s already exists.", chainedPath)
+		}
+		if err := os.Rename(hookPath, chainedPath); err != nil {
+			logger.Fatal("Failed to move existing hook: %v", err)
+		}
+		ui.PrintMessagef("Existing %s hook moved to %s and will run before git-ai.", hookName, filepath.Base(chainedPath))
+	} else if !os.IsNotExist(err) {
+		logger.Fatal("Failed to read existing hook: %v", err)
+	}
+
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		logger.Fatal("Failed to create hooks directory: %v", err)
+	}
+	if err := os.WriteFile(hookPath, []byte(hookScript), 0755); err != nil {
+		logger.Fatal("Failed to write hook: %v", err)
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Installed %s hook in %s", hookName, hooksDir))
+}
+
+func executeUninstall() {
+	hooksDir, err := git.GetHooksDir()
+	if err != nil {
+		logger.Fatal("Failed to locate hooks directory: %v", err)
+	}
+
+	hookPath := filepath.Join(hooksDir, hookName)
+	chainedPath := hookPath + chainedHookSuffix
+
+	existing, err := os.ReadFile(hookPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			ui.PrintMessagef("No %s hook installed in %s", hookName, hooksDir)
+			return
+		}
+		logger.Fatal("Failed to read hook: %v", err)
+	}
+
+	if !strings.Contains(string(existing), hookMarker) {
+		ui.PrintFatalf("The %s hook in %s was not installed by git-ai, leaving it untouched.", hookName, hooksDir)
+	}
+
+	if err := os.Remove(hookPath); err != nil {
+		logger.Fatal("Failed to remove hook: %v", err)
+	}
+
+	// Restore the hook git-ai was chained to
+	if _, err := os.Stat(chainedPath); err == nil {
+		if err := os.Rename(chainedPath, hookPath); err != nil {
+			logger.Fatal("Failed to restore previous hook: %v", err)
+		}
+		ui.PrintMessagef("Restored previous %s hook.", hookName)
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Removed %s hook from %s", hookName, hooksDir))
+}
+
+// executePrepareCommitMsg fills the commit message file with a generated message.
+// It never fails the commit: problems are reported on stderr and the file is left as is.
+func executePrepareCommitMsg(messageFile, source string) {
+	// Only fill in messages for plain commits; merges, amends, squashes,
+	// templates and messages given with -m/-F already have content
+	if source != "" {
+		logger.Debug("Skipping message generation for commit source %q", source)
+		return
+	}
+
+	if !git.HasStagedChanges() {
+		return
+	}
+
+	existing, err := os.ReadFile(messageFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "git-ai: could not read commit message file: %v\n", err)
+		return
+	}
+	if hasMessageContent(string(existing)) {
+		return
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "git-ai: could not load config: %v\n", err)
+		return
+	}
+
+	diff := git.GetStagedDiffFiltered(cfg)
+	if diff == "" {
+		return
+	}
+
+	// Mask secrets before anything is sent to the LLM
+	diff, secrets := git.RedactSecrets(cfg, diff)
+	if len(secrets) > 0 {
+		for _, secret := range secrets {
+			fmt.Fprintf(os.Stderr, "git-ai: possible secret in %s\n", secret)
+		}
+		if cfg.BlockOnSecrets {
+			fmt.Fprintln(os.Stderr, "git-ai: not generating a commit message because block_on_secrets is set")
+			return
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "git-ai: generating commit message...")
+	message, err := commit.GenerateCommitMessage(cfg, diff, git.GetRecentCommits(),
+		commit.ConventionalCommitsPreference(), commit.DescriptionsPreference())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "git-ai: could not generate commit message: %v\n", err)
+		return
+	}
+
+	if err := os.WriteFile(messageFile, []byte(message+"\n"+string(existing)), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "git-ai: could not write commit message file: %v\n", err)
+	}
+}
+
+// scissorsLine follows the comment character on the line that git commit --verbose puts
+// above the diff. Everything below it is removed from the message.
+const scissorsLine = " ------------------------ >8 ------------------------"
+
+// hasMessageContent reports whether the commit message file already contains
+// anything other than comments and blank lines
+func hasMessageContent(content string) bool {
+	commentChar, err := git.GetConfig("core.commentChar")
+	if err != nil || commentChar == "" || commentChar == "auto" {
+		commentChar = "#"
+	}
+	return messageHasContent(content, commentChar)
+}
+
+// messageHasContent reports whether a commit message has any lines other than comments
+// and blank lines, ignoring everything below the scissors line
+func messageHasContent(content, commentChar string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == commentChar+scissorsLine {
+			break
+		}
+		if trimmed != "" && !strings.HasPrefix(trimmed, commentChar) {
+			return true
+		}
+	}
+	return false
+}
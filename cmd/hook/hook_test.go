@@ -0,0 +1,34 @@
+package hook
+
+import "testing"
+
+func TestMessageHasContent(t *testing.T) {
+	verboseDiff := "# ------------------------ >8 ------------------------\n" +
+		"# Do not modify or remove the line above.\n" +
+		"diff --git a/main.go b/main.go\n" +
+		"+func main() {}\n"
+
+	tests := []struct {
+		name        string
+		content     string
+		commentChar string
+		expected    bool
+	}{
+		{"empty", "", "#", false},
+		{"comments only", "\n# Please enter the commit message\n#\n", "#", false},
+		{"message", "Fix the build\n\n# Please enter the commit message\n", "#", true},
+		{"verbose without message", "\n# Please enter the commit message\n" + verboseDiff, "#", false},
+		{"verbose with message", "Fix the build\n# Please enter the commit message\n" + verboseDiff, "#", true},
+		{"custom comment char", "\n; Please enter the commit message\n;\n", ";", false},
+		{"custom comment char verbose", "\n; Please enter the commit message\n; ------------------------ >8 ------------------------\ndiff --git a/main.go b/main.go\n", ";", false},
+		{"default comment char is content with a custom one", "# Not a comment\n", ";", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := messageHasContent(tt.content, tt.commentChar); result != tt.expected {
+				t.Errorf("messageHasContent(%q, %q) = %v, want %v", tt.content, tt.commentChar, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package resolve
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	autoApprove bool
+)
+
+// Cmd represents the resolve command
+var Cmd = &cobra.Command{
+	Use:   "resolve [file...]",
+	Short: "Resolve merge conflicts with AI assistance",
+	Long: `Finds files with merge conflicts, proposes a resolution for each conflict with a
+rationale, and writes and stages the file once every conflict in it is resolved.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		executeResolve(args)
+	},
+}
+
+func init() {
+	Cmd.Flags().BoolVar(&autoApprove, "auto", false, "Automatically accept proposed resolutions without prompting")
+}
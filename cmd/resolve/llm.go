@@ -0,0 +1,99 @@
+package resolve
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/git"
+	"github.com/recrsn/git-ai/pkg/llm"
+)
+
+// contextLines is the number of lines around a conflict sent to the LLM as context
+const contextLines = 15
+
+// conflictResolution is the LLM's proposal for a single conflict block
+type conflictResolution struct {
+	Resolution string `json:"resolution"`
+	Rationale  string `json:"rationale"`
+}
+
+// conflictPromptData collects the conflict sections and surrounding context for the prompt,
+// masking any secrets in them. Line numbers in the findings are 1-based file lines.
+func conflictPromptData(scanner *git.SecretScanner, path string, lines []string, block git.ConflictBlock) (llm.ResolvePromptData, []git.SecretFinding) {
+	beforeStart := max(block.StartLine-contextLines, 0)
+	afterEnd := min(block.EndLine+1+contextLines, len(lines))
+
+	var findings []git.SecretFinding
+	redact := func(firstLine int, text string) string {
+		redacted, sectionFindings := scanner.RedactText(path, firstLine, text)
+		findings = append(findings, sectionFindings...)
+		return redacted
+	}
+
+	// The base may come from a diff3 merge rather than the file, so its position is unknown
+	data := llm.ResolvePromptData{
+		Path:        path,
+		Before:      redact(beforeStart+1, joinContextLines(lines[beforeStart:block.StartLine])),
+		After:       redact(block.EndLine+2, joinContextLines(lines[block.EndLine+1:afterEnd])),
+		OursLabel:   block.OursLabel,
+		Ours:        redact(block.StartLine+2, block.Ours),
+		Base:        redact(0, block.Base),
+		TheirsLabel: block.TheirsLabel,
+		Theirs:      redact(block.EndLine-strings.Count(block.Theirs, "\n")+1, block.Theirs),
+	}
+	return data, findings
+}
+
+// generateResolution asks the LLM to resolve a single conflict block. Secrets masked by the
+// scanner are restored in the proposal so that they are kept in the resolved file.
+func generateResolution(cfg config.Config, client *llm.Client, scanner *git.SecretScanner, data llm.ResolvePromptData) (conflictResolution, error) {
+	userPrompt, err := llm.GetResolveUserPrompt(data)
+	if err != nil {
+		return conflictResolution{}, fmt.Errorf("failed to build user prompt: %w", err)
+	}
+
+	messages := []llm.Message{
+		{
+			Role:    "system",
+			Content: llm.GetResolveSystemPrompt(),
+		},
+		{
+			Role:    "user",
+			Content: userPrompt,
+		},
+	}
+
+	response, err := client.ChatCompletion(cfg.Model, messages)
+	if err != nil {
+		return conflictResolution{}, fmt.Errorf("failed to get completion: %w", err)
+	}
+
+	var resolution conflictResolution
+	if err := json.Unmarshal([]byte(llm.StripCodeFence(response)), &resolution); err != nil {
+		return conflictResolution{}, fmt.Errorf("failed to parse resolution: %w, response: %s", err, response)
+	}
+
+	resolution.Resolution = scanner.Restore(resolution.Resolution)
+	resolution.Rationale = scanner.Restore(resolution.Rationale)
+
+	if git.ParseConflicts(resolution.Resolution) != nil {
+		return conflictResolution{}, fmt.Errorf("proposed resolution still contains conflict markers")
+	}
+
+	// Resolutions replace whole lines, so make sure they end with a newline
+	if resolution.Resolution != "" && !strings.HasSuffix(resolution.Resolution, "\n") {
+		resolution.Resolution += "\n"
+	}
+
+	return resolution, nil
+}
+
+// joinContextLines joins context lines, keeping a trailing newline like conflict sections have
+func joinContextLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,238 @@
+package resolve
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/git"
+	"github.com/recrsn/git-ai/pkg/llm"
+	"github.com/recrsn/git-ai/pkg/logger"
+	"github.com/recrsn/git-ai/pkg/ui"
+)
+
+func executeResolve(args []string) {
+	cfg := config.LoadConfigOrFatal()
+	if cfg.APIKey == "" {
+		ui.PrintFatal("LLM endpoint or API key not configured. Please run 'git ai config' to set up.")
+	}
+
+	client, err := llm.NewClientWithProvider(cfg.Provider, cfg.Endpoint, cfg.APIKey)
+	if err != nil {
+		logger.Fatal("Failed to create LLM client: %v", err)
+	}
+
+	root, err := git.GetRepoRoot()
+	if err != nil {
+		logger.Fatal("Failed to locate repository root: %v", err)
+	}
+
+	// Work with paths relative to the repository root, like git diff reports them
+	var paths []string
+	if len(args) > 0 {
+		for _, arg := range args {
+			absPath, err := filepath.Abs(arg)
+			if err != nil {
+				logger.Fatal("Invalid path %s: %v", arg, err)
+			}
+			relPath, err := filepath.Rel(root, absPath)
+			if err != nil {
+				logger.Fatal("Invalid path %s: %v", arg, err)
+			}
+			paths = append(paths, filepath.ToSlash(relPath))
+		}
+	} else {
+		paths, err = git.GetConflictedFiles()
+		if err != nil {
+			logger.Fatal("Failed to get conflicted files: %v", err)
+		}
+	}
+
+	if len(paths) == 0 {
+		ui.PrintMessage("No conflicted files found.")
+		return
+	}
+
+	resolvedCount := 0
+	for _, path := range paths {
+		if git.IsExcludedPath(cfg, path) {
+			ui.PrintMessagef("Skipping %s, it is excluded from being sent to the LLM.", path)
+			continue
+		}
+		if resolveFile(cfg, client, root, path) {
+			resolvedCount++
+		}
+	}
+
+	ui.PrintMessagef("Resolved %d of %d conflicted files.", resolvedCount, len(paths))
+}
+
+// resolveFile walks through the conflicts of a single file and writes the accepted
+// resolutions. It returns true when every conflict was resolved and the file was staged.
+func resolveFile(cfg config.Config, client *llm.Client, root, path string) bool {
+	absPath := filepath.Join(root, path)
+	info, err := os.Stat(absPath)
+	if err != nil {
+		ui.PrintErrorf("Could not read %s: %v", path, err)
+		return false
+	}
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		ui.PrintErrorf("Could not read %s: %v", path, err)
+		return false
+	}
+	content := string(data)
+
+	blocks := git.ParseConflicts(content)
+	if len(blocks) == 0 {
+		ui.PrintMessagef("No conflict markers found in %s, skipping.", path)
+		return false
+	}
+	fillMissingBases(path, blocks)
+
+	ui.DisplaySection(fmt.Sprintf("%s (%d conflicts)", path, len(blocks)))
+
+	lines := strings.Split(content, "\n")
+	scanner := git.NewSecretScanner(cfg)
+	resolutions := make(map[int]string)
+	for i, block := range blocks {
+		// Mask secrets before anything is sent to the LLM
+		data, secrets := conflictPromptData(scanner, path, lines, block)
+		ui.ReportSecrets(secrets, cfg.BlockOnSecrets)
+
+		proposal, err := ui.WithSpinnerResult(fmt.Sprintf("Resolving conflict %d of %d with LLM...", i+1, len(blocks)), func() (conflictResolution, error) {
+			return generateResolution(cfg, client, scanner, data)
+		})
+		if err != nil {
+			ui.PrintErrorf("Could not propose a resolution for conflict %d in %s: %v", i+1, path, err)
+			continue
+		}
+
+		resolution, ok := reviewResolution(block, proposal)
+		if ok {
+			resolutions[i] = resolution
+		}
+	}
+
+	if len(resolutions) == 0 {
+		ui.PrintMessagef("No conflicts resolved in %s.", path)
+		return false
+	}
+
+	if err := os.WriteFile(absPath, []byte(git.ResolveConflicts(content, resolutions)), info.Mode().Perm()); err != nil {
+		ui.PrintErrorf("Could not write %s: %v", path, err)
+		return false
+	}
+
+	if len(resolutions) < len(blocks) {
+		ui.PrintMessagef("Resolved %d of %d conflicts in %s, the remaining conflicts are left in place.", len(resolutions), len(blocks), path)
+		return false
+	}
+
+	if err := git.StageFile(absPath); err != nil {
+		ui.PrintErrorf("Resolved %s but could not stage it: %v", path, err)
+		return false
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Resolved and staged %s", path))
+	return true
+}
+
+// fillMissingBases adds the common ancestor text to conflict blocks written without
+// the diff3 conflict style by re-merging the index stages of the file
+func fillMissingBases(path string, blocks []git.ConflictBlock) {
+	missing := false
+	for _, block := range blocks {
+		if !block.HasBase {
+			missing = true
+			break
+		}
+	}
+	if !missing {
+		return
+	}
+
+	merged, err := git.GetDiff3Conflict(path)
+	if err != nil {
+		logger.Debug("Could not compute base for conflicts in %s: %v", path, err)
+		return
+	}
+
+	// Only use blocks that match what is in the working tree
+	diff3Blocks := git.ParseConflicts(merged)
+	for i := range blocks {
+		if blocks[i].HasBase {
+			continue
+		}
+		for _, diff3Block := range diff3Blocks {
+			if diff3Block.HasBase && diff3Block.Ours == blocks[i].Ours && diff3Block.Theirs == blocks[i].Theirs {
+				blocks[i].Base = diff3Block.Base
+				blocks[i].HasBase = true
+				break
+			}
+		}
+	}
+}
+
+// reviewResolution shows the conflict next to the proposed resolution and lets the user
+// accept it, pick a side, edit it or skip the conflict
+func reviewResolution(block git.ConflictBlock, proposal conflictResolution) (string, bool) {
+	ui.DisplaySideBySide(sideTitle("Ours", block.OursLabel), block.Ours, sideTitle("Theirs", block.TheirsLabel), block.Theirs)
+	if block.HasBase {
+		ui.DisplayBox("Base", displayText(block.Base))
+	}
+	ui.DisplayBox("Proposed Resolution", displayText(proposal.Resolution))
+	if proposal.Rationale != "" {
+		ui.DisplayInfo(proposal.Rationale)
+	}
+
+	if autoApprove {
+		return proposal.Resolution, true
+	}
+
+	options := []string{"Accept resolution", "Keep ours", "Keep theirs", "Edit resolution", "Skip"}
+	selectedOption, err := ui.PromptForSelection(options, "Accept resolution", "What would you like to do?")
+	if err != nil {
+		logger.Fatal("Error prompting for selection: %v", err)
+	}
+
+	switch selectedOption {
+	case "Accept resolution":
+		return proposal.Resolution, true
+	case "Keep ours":
+		return block.Ours, true
+	case "Keep theirs":
+		return block.Theirs, true
+	case "Edit resolution":
+		edited, err := git.EditWithExternalEditor(proposal.Resolution)
+		if err != nil {
+			ui.PrintErrorf("Error opening external editor: %v", err)
+			return "", false
+		}
+		if git.ParseConflicts(edited) != nil {
+			ui.PrintError("Edited resolution still contains conflict markers, skipping.")
+			return "", false
+		}
+		return edited, true
+	}
+
+	return "", false
+}
+
+// sideTitle builds a box title for one side of a conflict
+func sideTitle(side, label string) string {
+	if label == "" {
+		return side
+	}
+	return fmt.Sprintf("%s (%s)", side, label)
+}
+
+// displayText makes empty conflict sections visible in boxes
+func displayText(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return "(empty)"
+	}
+	return text
+}
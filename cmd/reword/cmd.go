@@ -0,0 +1,34 @@
+package reword
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	autoApprove             bool
+	force                   bool
+	conventionalCommits     bool
+	noConventionalCommits   bool
+	commitsWithDescriptions bool
+)
+
+// Cmd represents the reword command
+var Cmd = &cobra.Command{
+	Use:   "reword <range>",
+	Short: "Regenerate messages for existing commits",
+	Long: `Regenerates the message of each commit in a range from its own diff and rewrites
+the history with the approved messages. The range is either <base>..HEAD or a single
+<base> meaning <base>..HEAD. Commits that are already pushed are refused unless --force is given.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		executeReword(args[0])
+	},
+}
+
+func init() {
+	Cmd.Flags().BoolVar(&autoApprove, "auto", false, "Automatically use the generated messages without prompting")
+	Cmd.Flags().BoolVar(&force, "force", false, "Allow rewording commits that are already pushed")
+	Cmd.Flags().BoolVar(&conventionalCommits, "conventional", false, "Use conventional commit format (type(scope): description)")
+	Cmd.Flags().BoolVar(&noConventionalCommits, "no-conventional", false, "Don't use conventional commit format")
+	Cmd.Flags().BoolVar(&commitsWithDescriptions, "with-descriptions", false, "Generate commit messages with detailed descriptions")
+}
@@ -0,0 +1,215 @@
+package reword
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/recrsn/git-ai/cmd/commit"
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/git"
+	"github.com/recrsn/git-ai/pkg/logger"
+	"github.com/recrsn/git-ai/pkg/ui"
+)
+
+func executeReword(revRange string) {
+	cfg := config.LoadConfigOrFatal()
+
+	base, commits := resolveRange(revRange)
+	if len(commits) == 0 {
+		ui.PrintMessage("No commits found in range.")
+		return
+	}
+
+	hasMerges, err := git.HasMergeCommits(base, "HEAD")
+	if err != nil {
+		logger.Fatal("Failed to inspect range: %v", err)
+	}
+	if hasMerges {
+		ui.PrintFatal("The range contains merge commits, which reword cannot rewrite.")
+	}
+
+	if !force {
+		unpushed, err := git.GetUnpushedCommits(base, "HEAD")
+		if err != nil {
+			logger.Fatal("Failed to check for pushed commits: %v", err)
+		}
+		if pushed := len(commits) - len(unpushed); pushed > 0 {
+			ui.PrintFatalf("%d of the commits in the range are already pushed. Use --force to reword them anyway.", pushed)
+		}
+	}
+
+	// Resolve commit message style
+	useConventionalCommits := commit.ConventionalCommitsPreference()
+	if conventionalCommits {
+		useConventionalCommits = true
+	} else if noConventionalCommits {
+		useConventionalCommits = false
+	}
+	if !commitsWithDescriptions {
+		commitsWithDescriptions = commit.DescriptionsPreference()
+	}
+
+	var infos []git.CommitInfo
+	newMessages := make(map[string]string)
+	for i, hash := range commits {
+		info, err := git.GetCommitInfo(hash)
+		if err != nil {
+			logger.Fatal("Failed to read commit %s: %v", hash, err)
+		}
+		infos = append(infos, info)
+
+		diff := git.FilterGeneratedDiff(cfg, git.GetCommitDiff(hash))
+		if diff == "" {
+			ui.PrintMessagef("Commit %s has no reviewable changes, keeping its message.", shortHash(hash))
+			continue
+		}
+
+		// Mask secrets before anything is sent to the LLM
+		diff, secrets := git.RedactSecrets(cfg, diff)
+		ui.ReportSecrets(secrets, cfg.BlockOnSecrets)
+
+		message, err := ui.WithSpinnerResult(fmt.Sprintf("Generating message for commit %s (%d of %d)...", shortHash(hash), i+1, len(commits)), func() (string, error) {
+			return commit.GenerateMessageForDiff(cfg, diff, git.GetCommitChangedFiles(cfg, hash), git.GetRecentCommitsBefore(hash), useConventionalCommits, commitsWithDescriptions)
+		})
+		if err != nil {
+			if errors.Is(err, config.ErrLLMNotConfigured) {
+				ui.PrintFatal("LLM endpoint or API key not configured. Please run 'git ai config' to set up.")
+			}
+			logger.Fatal("Failed to generate commit message: %v", err)
+		}
+
+		if !autoApprove {
+			var keep bool
+			message, keep = reviewMessage(info, message)
+			if !keep {
+				continue
+			}
+		}
+
+		if strings.TrimSpace(message) != info.Message {
+			newMessages[hash] = strings.TrimSpace(message)
+		}
+	}
+
+	if len(newMessages) == 0 {
+		ui.PrintMessage("No commits reworded.")
+		return
+	}
+
+	rewriteHistory(infos, newMessages)
+	ui.PrintSuccess(fmt.Sprintf("Reworded %d commits successfully!", len(newMessages)))
+}
+
+// resolveRange parses "<base>..HEAD" or "<base>" and returns the base commit
+// and the commits to reword, oldest first
+func resolveRange(revRange string) (string, []string) {
+	baseRev, tipRev := revRange, "HEAD"
+	if before, after, found := strings.Cut(revRange, ".."); found {
+		baseRev, tipRev = before, after
+		if tipRev == "" {
+			tipRev = "HEAD"
+		}
+	}
+
+	base, err := git.ResolveCommit(baseRev)
+	if err != nil {
+		ui.PrintFatalf("Invalid range %s: %v", revRange, err)
+	}
+
+	tip, err := git.ResolveCommit(tipRev)
+	if err != nil {
+		ui.PrintFatalf("Invalid range %s: %v", revRange, err)
+	}
+
+	head, err := git.GetLatestCommitHash()
+	if err != nil {
+		logger.Fatal("Failed to resolve HEAD: %v", err)
+	}
+	if tip != head {
+		ui.PrintFatal("The range must end at HEAD.")
+	}
+
+	commits, err := git.GetCommitsInRange(base, head)
+	if err != nil {
+		logger.Fatal("Failed to list commits: %v", err)
+	}
+	return base, commits
+}
+
+// reviewMessage shows the current and proposed message side by side and lets the user choose.
+// It returns false if the current message should be kept.
+func reviewMessage(info git.CommitInfo, proposed string) (string, bool) {
+	ui.DisplaySideBySide(fmt.Sprintf("Current (%s)", shortHash(info.Hash)), info.Message, "Proposed", proposed)
+
+	options := []string{"Use new message", "Edit message", "Keep current message", "Cancel"}
+	selectedOption, err := ui.PromptForSelection(options, "Use new message", "What would you like to do?")
+	if err != nil {
+		logger.Fatal("Error prompting for selection: %v", err)
+	}
+
+	switch selectedOption {
+	case "Use new message":
+		return proposed, true
+	case "Edit message":
+		edited, err := git.EditWithExternalEditor(proposed)
+		if err != nil {
+			logger.Fatal("Error opening external editor: %v", err)
+		}
+		if strings.TrimSpace(edited) == "" {
+			ui.PrintMessage("Empty message, keeping the current one.")
+			return "", false
+		}
+		return edited, true
+	case "Keep current message":
+		return "", false
+	}
+
+	ui.PrintMessage("Reword cancelled, no commits were changed.")
+	ui.Exit(0)
+	return "", false
+}
+
+// rewriteHistory replays the commits with their new messages on top of their rewritten
+// parents and moves HEAD to the new tip. Trees are unchanged, so the working tree and
+// index are not touched.
+func rewriteHistory(infos []git.CommitInfo, newMessages map[string]string) {
+	rewritten := make(map[string]string)
+	for _, info := range infos {
+		parent := ""
+		if len(info.Parents) > 0 {
+			parent = info.Parents[0]
+		}
+		newParent, parentRewritten := rewritten[parent]
+		message, reworded := newMessages[info.Hash]
+
+		if !parentRewritten && !reworded {
+			continue
+		}
+		if parentRewritten {
+			parent = newParent
+		}
+		if !reworded {
+			message = info.Message
+		}
+
+		newHash, err := git.CreateCommitFromInfo(info, parent, message)
+		if err != nil {
+			logger.Fatal("Failed to rewrite commit %s: %v", shortHash(info.Hash), err)
+		}
+		rewritten[info.Hash] = newHash
+	}
+
+	oldHead := infos[len(infos)-1].Hash
+	if err := git.UpdateHead(rewritten[oldHead], oldHead, "git-ai reword"); err != nil {
+		logger.Fatal("Failed to update HEAD: %v", err)
+	}
+}
+
+// shortHash abbreviates a commit hash for display
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
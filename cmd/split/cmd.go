@@ -0,0 +1,31 @@
+package split
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	autoApprove             bool
+	conventionalCommits     bool
+	noConventionalCommits   bool
+	commitsWithDescriptions bool
+)
+
+// Cmd represents the split command
+var Cmd = &cobra.Command{
+	Use:   "split",
+	Short: "Split staged changes into multiple logical commits",
+	Long: `Analyzes the hunks of your staged changes, proposes how to group them into
+logical commits with a message for each, and creates the commits in order after approval.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		executeSplit()
+	},
+}
+
+func init() {
+	Cmd.Flags().BoolVar(&autoApprove, "auto", false, "Automatically apply the proposed plan without prompting")
+	Cmd.Flags().BoolVar(&conventionalCommits, "conventional", false, "Use conventional commit format (type(scope): description)")
+	Cmd.Flags().BoolVar(&noConventionalCommits, "no-conventional", false, "Don't use conventional commit format")
+	Cmd.Flags().BoolVar(&commitsWithDescriptions, "with-descriptions", false, "Generate commit messages with detailed descriptions")
+}
@@ -0,0 +1,145 @@
+package split
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/git"
+	"github.com/recrsn/git-ai/pkg/llm"
+	"github.com/recrsn/git-ai/pkg/logger"
+)
+
+// maxHunkPromptLength limits how much of a single hunk is sent to the LLM
+const maxHunkPromptLength = 4000
+
+// splitCommit is a single commit of a proposed split plan
+type splitCommit struct {
+	Message string `json:"message"`
+	Hunks   []int  `json:"hunks"`
+}
+
+// splitPlan is the LLM's proposal for splitting the staged changes
+type splitPlan struct {
+	Commits []splitCommit `json:"commits"`
+}
+
+// generateSplitPlan asks the LLM to group the staged hunks into logical commits
+func generateSplitPlan(cfg config.Config, hunks []git.DiffHunk, recentCommits string, useConventionalCommits, commitsWithDescriptions bool) ([]splitCommit, error) {
+	if cfg.APIKey == "" {
+		return nil, config.ErrLLMNotConfigured
+	}
+
+	client, err := llm.NewClientWithProvider(cfg.Provider, cfg.Endpoint, cfg.APIKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	systemPrompt, err := llm.GetSplitSystemPrompt(useConventionalCommits, commitsWithDescriptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build system prompt: %w", err)
+	}
+
+	userPrompt, err := llm.GetSplitUserPrompt(formatHunksForPrompt(hunks), recentCommits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build user prompt: %w", err)
+	}
+
+	messages := []llm.Message{
+		{
+			Role:    "system",
+			Content: systemPrompt,
+		},
+		{
+			Role:    "user",
+			Content: userPrompt,
+		},
+	}
+
+	response, err := client.ChatCompletion(cfg.Model, messages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get completion: %w", err)
+	}
+
+	var plan splitPlan
+	if err := json.Unmarshal([]byte(llm.StripCodeFence(response)), &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse split plan: %w, response: %s", err, response)
+	}
+
+	return normalizePlan(plan.Commits, hunks)
+}
+
+// formatHunksForPrompt renders the numbered hunks for the split prompt
+func formatHunksForPrompt(hunks []git.DiffHunk) string {
+	var result strings.Builder
+	for _, hunk := range hunks {
+		result.WriteString(fmt.Sprintf("## Hunk %d: %s\n```diff\n", hunk.ID, hunk.Path))
+
+		content := hunk.Content
+		if content == "" {
+			// Header-only change; leave out binary patch data
+			content = hunk.Header
+			if idx := strings.Index(content, "GIT binary patch"); idx != -1 {
+				content = content[:idx] + "Binary file changed\n"
+			}
+		}
+		if len(content) > maxHunkPromptLength {
+			content = content[:maxHunkPromptLength] + "\n... (truncated)\n"
+		}
+
+		result.WriteString(content)
+		if !strings.HasSuffix(content, "\n") {
+			result.WriteString("\n")
+		}
+		result.WriteString("```\n\n")
+	}
+	return result.String()
+}
+
+// normalizePlan makes sure every hunk is assigned to exactly one commit, dropping
+// unknown and duplicate references and assigning leftovers to the last commit
+func normalizePlan(commits []splitCommit, hunks []git.DiffHunk) ([]splitCommit, error) {
+	known := make(map[int]bool, len(hunks))
+	for _, hunk := range hunks {
+		known[hunk.ID] = true
+	}
+
+	assigned := make(map[int]bool, len(hunks))
+	var result []splitCommit
+	for _, commit := range commits {
+		var ids []int
+		for _, id := range commit.Hunks {
+			if !known[id] {
+				logger.Warn("Ignoring unknown hunk %d in split plan", id)
+				continue
+			}
+			if assigned[id] {
+				logger.Warn("Ignoring duplicate assignment of hunk %d in split plan", id)
+				continue
+			}
+			assigned[id] = true
+			ids = append(ids, id)
+		}
+
+		message := strings.TrimSpace(commit.Message)
+		if len(ids) == 0 || message == "" {
+			continue
+		}
+		result = append(result, splitCommit{Message: message, Hunks: ids})
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("split plan does not contain any commits")
+	}
+
+	for _, hunk := range hunks {
+		if !assigned[hunk.ID] {
+			logger.Warn("Hunk %d (%s) was not assigned by the LLM, adding it to the last commit", hunk.ID, hunk.Path)
+			last := &result[len(result)-1]
+			last.Hunks = append(last.Hunks, hunk.ID)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,162 @@
+package split
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/recrsn/git-ai/cmd/commit"
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/git"
+	"github.com/recrsn/git-ai/pkg/logger"
+	"github.com/recrsn/git-ai/pkg/ui"
+)
+
+func executeSplit() {
+	cfg := config.LoadConfigOrFatal()
+
+	if !git.HasStagedChanges() {
+		ui.PrintFatalf("No staged changes found. Please stage your changes with 'git add' first.")
+	}
+
+	hunks := git.ParseDiffHunks(git.GetStagedPatch())
+	if len(hunks) == 0 {
+		logger.Fatal("Could not retrieve diff of staged changes.")
+	}
+	if len(hunks) == 1 {
+		ui.PrintMessage("Only one hunk is staged, nothing to split. Use 'git ai commit' instead.")
+		ui.Exit(0)
+	}
+
+	// Resolve commit message style
+	useConventionalCommits := commit.ConventionalCommitsPreference()
+	if conventionalCommits {
+		useConventionalCommits = true
+	} else if noConventionalCommits {
+		useConventionalCommits = false
+	}
+	if !commitsWithDescriptions {
+		commitsWithDescriptions = commit.DescriptionsPreference()
+	}
+
+	recentCommits := git.GetRecentCommits()
+
+	// Mask excluded files and secrets in the copy sent to the LLM; the original hunks are applied later
+	promptHunks, secrets := git.NewSecretScanner(cfg).RedactHunks(git.MaskExcludedHunks(cfg, hunks))
+	ui.ReportSecrets(secrets, cfg.BlockOnSecrets)
+
+	plan, err := ui.WithSpinnerResult(fmt.Sprintf("Planning commits for %d hunks with LLM...", len(hunks)), func() ([]splitCommit, error) {
+		return generateSplitPlan(cfg, promptHunks, recentCommits, useConventionalCommits, commitsWithDescriptions)
+	})
+	if err != nil {
+		if errors.Is(err, config.ErrLLMNotConfigured) {
+			ui.PrintFatal("LLM endpoint or API key not configured. Please run 'git ai config' to set up.")
+		}
+		logger.Fatal("Failed to generate split plan: %v", err)
+	}
+
+	hunksByID := make(map[int]git.DiffHunk, len(hunks))
+	for _, hunk := range hunks {
+		hunksByID[hunk.ID] = hunk
+	}
+
+	if !autoApprove {
+		plan = reviewPlan(plan, hunksByID)
+	}
+
+	applyPlan(plan, hunksByID)
+}
+
+// reviewPlan previews the plan and lets the user apply, edit or cancel it
+func reviewPlan(plan []splitCommit, hunksByID map[int]git.DiffHunk) []splitCommit {
+	for {
+		displayPlan(plan, hunksByID)
+
+		options := []string{"Apply plan", "Edit messages", "Cancel"}
+		selectedOption, err := ui.PromptForSelection(options, "Apply plan", "What would you like to do?")
+		if err != nil {
+			logger.Fatal("Error prompting for selection: %v", err)
+		}
+
+		switch selectedOption {
+		case "Apply plan":
+			return plan
+		case "Edit messages":
+			for i := range plan {
+				edited, err := git.EditWithExternalEditor(plan[i].Message)
+				if err != nil {
+					logger.Fatal("Error opening external editor: %v", err)
+				}
+				if strings.TrimSpace(edited) == "" {
+					ui.PrintFatal("Commit message cannot be empty.")
+				}
+				plan[i].Message = strings.TrimSpace(edited)
+			}
+		case "Cancel":
+			ui.PrintMessage("Split cancelled.")
+			ui.Exit(0)
+		}
+	}
+}
+
+// displayPlan shows each proposed commit with the hunks it contains
+func displayPlan(plan []splitCommit, hunksByID map[int]git.DiffHunk) {
+	for i, c := range plan {
+		var content strings.Builder
+		content.WriteString(c.Message)
+		content.WriteString("\n")
+		for _, id := range c.Hunks {
+			content.WriteString(fmt.Sprintf("\n  • %s (hunk %d)", hunksByID[id].Path, id))
+		}
+		ui.DisplayBox(fmt.Sprintf("Commit %d of %d", i+1, len(plan)), content.String())
+	}
+}
+
+// applyPlan unstages everything and then stages and commits each group of hunks in order.
+// If anything fails, the remaining changes are restored to the index.
+func applyPlan(plan []splitCommit, hunksByID map[int]git.DiffHunk) {
+	originalTree, err := git.WriteIndexTree()
+	if err != nil {
+		logger.Fatal("Failed to save the current index: %v", err)
+	}
+
+	restoreIndex := func() {
+		// The original tree contains all staged changes; after partial commits
+		// it leaves exactly the not-yet-committed changes staged
+		if err := git.ReadTreeIntoIndex(originalTree); err != nil {
+			logger.Error("Failed to restore the index, your staged changes are saved in tree %s: %v", originalTree, err)
+		}
+	}
+
+	if err := git.ResetIndex(); err != nil {
+		restoreIndex()
+		logger.Fatal("Failed to reset the index: %v", err)
+	}
+
+	groups := make([][]git.DiffHunk, len(plan))
+	for i, c := range plan {
+		for _, id := range c.Hunks {
+			groups[i] = append(groups[i], hunksByID[id])
+		}
+	}
+	patches := git.BuildPatches(groups)
+
+	for i, c := range plan {
+		if err := git.ApplyPatchToIndex(patches[i]); err != nil {
+			restoreIndex()
+			ui.PrintFatalf("Failed to stage hunks for commit %d: %v", i+1, err)
+		}
+
+		if err := git.CreateCommit(c.Message, false); err != nil {
+			restoreIndex()
+			ui.PrintFatalf("Failed to create commit %d: %v", i+1, err)
+		}
+
+		commitHash, err := git.GetLatestCommitHash()
+		if err == nil {
+			logger.Debug("Commit created: %s", commitHash)
+		}
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Created %d commits successfully!", len(plan)))
+}
@@ -0,0 +1,39 @@
+package squash
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	autoApprove             bool
+	printOnly               bool
+	conventionalCommits     bool
+	noConventionalCommits   bool
+	commitsWithDescriptions bool
+)
+
+// Cmd represents the squash command
+var Cmd = &cobra.Command{
+	Use:   "squash [base]",
+	Short: "Squash the current branch into a single commit with a generated message",
+	Long: `Generates one coherent commit message from the combined diff and the individual
+commit messages of the current branch since it diverged from base (default: the remote's
+default branch, main or master), then squashes the branch into a single commit.
+Use --print to only print the message, e.g. for 'git merge --squash'.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		base := ""
+		if len(args) > 0 {
+			base = args[0]
+		}
+		executeSquash(base)
+	},
+}
+
+func init() {
+	Cmd.Flags().BoolVar(&autoApprove, "auto", false, "Automatically squash with the generated message without prompting")
+	Cmd.Flags().BoolVar(&printOnly, "print", false, "Only print the generated message without squashing")
+	Cmd.Flags().BoolVar(&conventionalCommits, "conventional", false, "Use conventional commit format (type(scope): description)")
+	Cmd.Flags().BoolVar(&noConventionalCommits, "no-conventional", false, "Don't use conventional commit format")
+	Cmd.Flags().BoolVar(&commitsWithDescriptions, "with-descriptions", false, "Generate commit messages with detailed descriptions")
+}
@@ -0,0 +1,63 @@
+package squash
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/git"
+	"github.com/recrsn/git-ai/pkg/llm"
+	"github.com/recrsn/git-ai/pkg/logger"
+)
+
+// generateSquashMessage generates a single commit message for the combined changes of several commits.
+// progress, if not nil, is called with the status of summarizing a large diff.
+func generateSquashMessage(cfg config.Config, diff, changedFiles string, commitMessages []string, useConventionalCommits, commitsWithDescriptions bool, progress git.SummaryProgress) (string, error) {
+	if cfg.APIKey == "" {
+		return "", config.ErrLLMNotConfigured
+	}
+
+	client, err := llm.NewClientWithProvider(cfg.Provider, cfg.Endpoint, cfg.APIKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	// Process diff with summarization if needed (32k token limit)
+	processedDiff, isSummarized, err := git.ProcessDiffWithSummarization(cfg, diff, 32000, progress)
+	if err != nil {
+		logger.Warn("Failed to process diff with summarization, using original: %v", err)
+		processedDiff = diff
+		isSummarized = false
+	}
+	if isSummarized && progress != nil {
+		progress("Generating commit message")
+	}
+
+	systemPrompt, err := llm.GetSystemPrompt(useConventionalCommits, commitsWithDescriptions, isSummarized)
+	if err != nil {
+		return "", fmt.Errorf("failed to build system prompt: %w", err)
+	}
+
+	userPrompt, err := llm.GetSquashUserPrompt(processedDiff, changedFiles, commitMessages)
+	if err != nil {
+		return "", fmt.Errorf("failed to build user prompt: %w", err)
+	}
+
+	messages := []llm.Message{
+		{
+			Role:    "system",
+			Content: systemPrompt,
+		},
+		{
+			Role:    "user",
+			Content: userPrompt,
+		},
+	}
+
+	response, err := client.ChatCompletion(cfg.Model, messages)
+	if err != nil {
+		return "", fmt.Errorf("failed to get completion: %w", err)
+	}
+
+	return strings.TrimSpace(response), nil
+}
@@ -0,0 +1,112 @@
+package squash
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/recrsn/git-ai/cmd/commit"
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/git"
+	"github.com/recrsn/git-ai/pkg/logger"
+	"github.com/recrsn/git-ai/pkg/ui"
+)
+
+func executeSquash(base string) {
+	cfg := config.LoadConfigOrFatal()
+
+	if base == "" {
+		var err error
+		base, err = git.GetDefaultBaseBranch()
+		if err != nil {
+			ui.PrintFatalf("%v", err)
+		}
+	}
+
+	mergeBase, err := git.GetMergeBase(base, "HEAD")
+	if err != nil {
+		ui.PrintFatalf("Could not find a common ancestor with %s: %v", base, err)
+	}
+
+	commitMessages, err := git.GetCommitMessagesInRange(mergeBase, "HEAD")
+	if err != nil {
+		logger.Fatal("Failed to get commit messages: %v", err)
+	}
+	if len(commitMessages) == 0 {
+		ui.PrintMessagef("No commits since %s, nothing to squash.", base)
+		return
+	}
+
+	// Anything already staged would silently end up in the squashed commit
+	if !printOnly && git.HasStagedChanges() {
+		ui.PrintFatal("You have staged changes. Please commit or unstage them before squashing.")
+	}
+
+	diff := git.FilterGeneratedDiff(cfg, git.GetDiffBetween(mergeBase, "HEAD"))
+	if diff == "" {
+		logger.Fatal("Could not retrieve diff of the branch.")
+	}
+
+	// Mask secrets before anything is sent to the LLM
+	diff, secrets := git.RedactSecrets(cfg, diff)
+	ui.ReportSecrets(secrets, cfg.BlockOnSecrets)
+
+	// Resolve commit message style
+	useConventionalCommits := commit.ConventionalCommitsPreference()
+	if conventionalCommits {
+		useConventionalCommits = true
+	} else if noConventionalCommits {
+		useConventionalCommits = false
+	}
+	if !commitsWithDescriptions {
+		commitsWithDescriptions = commit.DescriptionsPreference()
+	}
+
+	message, err := ui.WithProgressSpinner(fmt.Sprintf("Generating message for %d commits with LLM...", len(commitMessages)), func(update func(string)) (string, error) {
+		return generateSquashMessage(cfg, diff, git.GetChangedFilesBetween(cfg, mergeBase, "HEAD"), commitMessages, useConventionalCommits, commitsWithDescriptions, func(status string) {
+			update(status + " with LLM...")
+		})
+	})
+	if err != nil {
+		if errors.Is(err, config.ErrLLMNotConfigured) {
+			ui.PrintFatal("LLM endpoint or API key not configured. Please run 'git ai config' to set up.")
+		}
+		logger.Fatal("Failed to generate commit message: %v", err)
+	}
+
+	if printOnly {
+		ui.SetMessageResult(message)
+		if !ui.IsJSONOutput() {
+			fmt.Println(message)
+		}
+		return
+	}
+
+	if !autoApprove {
+		var proceed bool
+		message, proceed = ui.PromptForConfirmation(message)
+		if !proceed {
+			ui.Exit(0)
+		}
+	}
+
+	ui.SetMessageResult(message)
+
+	oldHead, err := git.GetLatestCommitHash()
+	if err != nil {
+		logger.Fatal("Failed to resolve HEAD: %v", err)
+	}
+
+	if err := git.SoftReset(mergeBase); err != nil {
+		logger.Fatal("Failed to reset to %s: %v", mergeBase, err)
+	}
+
+	if err := git.CreateCommit(message, false); err != nil {
+		// Put the branch back where it was
+		if resetErr := git.SoftReset(oldHead); resetErr != nil {
+			logger.Error("Failed to restore branch to %s: %v", oldHead, resetErr)
+		}
+		logger.Fatal("Failed to create commit: %v", err)
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Squashed %d commits successfully!", len(commitMessages)))
+}
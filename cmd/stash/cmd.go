@@ -0,0 +1,39 @@
+package stash
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	autoApprove      bool
+	includeUntracked bool
+)
+
+// Cmd represents the stash command
+var Cmd = &cobra.Command{
+	Use:   "stash",
+	Short: "Stash local changes with a generated description",
+	Long:  `Analyzes your staged and unstaged changes and stashes them with a short descriptive message.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		executeStash()
+	},
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stashes, relabeling anonymous ones by their contents",
+	Long: `Lists the stash entries and generates descriptive labels for anonymous "WIP on ..."
+stashes from their contents. The new labels are saved to the stash list after approval.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		executeStashList()
+	},
+}
+
+func init() {
+	Cmd.PersistentFlags().BoolVar(&autoApprove, "auto", false, "Automatically use the generated messages without prompting")
+	Cmd.Flags().BoolVarP(&includeUntracked, "include-untracked", "u", false, "Also stash untracked files")
+
+	Cmd.AddCommand(listCmd)
+}
@@ -0,0 +1,70 @@
+package stash
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/git"
+	"github.com/recrsn/git-ai/pkg/llm"
+	"github.com/recrsn/git-ai/pkg/logger"
+)
+
+// generateStashMessage generates a short label describing the changes in a diff and the
+// newline-separated list of untracked files stashed with them.
+// progress, if not nil, is called with the status of summarizing a large diff.
+func generateStashMessage(cfg config.Config, diff, untrackedFiles string, progress git.SummaryProgress) (string, error) {
+	if cfg.APIKey == "" {
+		return "", config.ErrLLMNotConfigured
+	}
+
+	client, err := llm.NewClientWithProvider(cfg.Provider, cfg.Endpoint, cfg.APIKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	// Process diff with summarization if needed (32k token limit)
+	processedDiff, isSummarized, err := git.ProcessDiffWithSummarization(cfg, diff, 32000, progress)
+	if err != nil {
+		logger.Warn("Failed to process diff with summarization, using original: %v", err)
+		processedDiff = diff
+		isSummarized = false
+	}
+	if isSummarized && progress != nil {
+		progress("Generating stash message")
+	}
+
+	messages := []llm.Message{
+		{
+			Role:    "system",
+			Content: llm.GetStashSystemPrompt(),
+		},
+		{
+			Role:    "user",
+			Content: stashUserPrompt(processedDiff, untrackedFiles),
+		},
+	}
+
+	response, err := client.ChatCompletion(cfg.Model, messages)
+	if err != nil {
+		return "", fmt.Errorf("failed to get completion: %w", err)
+	}
+
+	// Labels are a single line
+	label, _, _ := strings.Cut(strings.TrimSpace(response), "\n")
+	return strings.Trim(strings.TrimSpace(label), "`\""), nil
+}
+
+// stashUserPrompt asks for a label for the diff and the untracked files, leaving out
+// whichever is empty
+func stashUserPrompt(diff, untrackedFiles string) string {
+	var prompt strings.Builder
+	prompt.WriteString("Write a label for the work in progress in these changes.")
+	if diff != "" {
+		fmt.Fprintf(&prompt, "\n\nDiff of tracked files:\n\n```diff\n%s\n```", diff)
+	}
+	if untrackedFiles = strings.TrimSpace(untrackedFiles); untrackedFiles != "" {
+		fmt.Fprintf(&prompt, "\n\nNew untracked files, contents not shown:\n\n```\n%s\n```", untrackedFiles)
+	}
+	return prompt.String()
+}
@@ -0,0 +1,142 @@
+package stash
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/git"
+	"github.com/recrsn/git-ai/pkg/logger"
+	"github.com/recrsn/git-ai/pkg/ui"
+)
+
+func executeStash() {
+	cfg := config.LoadConfigOrFatal()
+
+	diff := git.FilterGeneratedDiff(cfg, git.GetStagedDiff()+git.GetUnstagedDiff())
+
+	// Untracked files don't show up in the diff, so they are listed by name
+	var untrackedFiles string
+	if includeUntracked {
+		untracked := git.GetUntrackedFiles()
+		if diff == "" && strings.TrimSpace(untracked) == "" {
+			ui.PrintMessage("No local changes to stash.")
+			return
+		}
+		untrackedFiles = git.FilterExcludedFiles(cfg, untracked)
+	} else if diff == "" {
+		ui.PrintMessage("No local changes to stash.")
+		return
+	}
+
+	// Mask secrets before anything is sent to the LLM
+	diff, secrets := git.RedactSecrets(cfg, diff)
+	ui.ReportSecrets(secrets, cfg.BlockOnSecrets)
+
+	message, err := ui.WithProgressSpinner("Generating stash message with LLM...", func(update func(string)) (string, error) {
+		return generateStashMessage(cfg, diff, untrackedFiles, func(status string) {
+			update(status + " with LLM...")
+		})
+	})
+	if err != nil {
+		exitOnLLMError(err)
+	}
+
+	if !autoApprove {
+		ui.DisplayBox("Generated Stash Message", message)
+
+		options := []string{"Stash", "Edit message", "Cancel"}
+		selectedOption, err := ui.PromptForSelection(options, "Stash", "What would you like to do?")
+		if err != nil {
+			logger.Fatal("Error prompting for selection: %v", err)
+		}
+
+		switch selectedOption {
+		case "Edit message":
+			message, err = ui.PromptForInput("Edit stash message:", message)
+			if err != nil {
+				logger.Fatal("Error prompting for input: %v", err)
+			}
+		case "Cancel":
+			ui.PrintMessage("Stash cancelled.")
+			ui.Exit(0)
+		}
+	}
+
+	ui.SetResult("message", message)
+
+	if err := git.StashPush(message, includeUntracked); err != nil {
+		logger.Fatal("Failed to stash changes: %v", err)
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Stashed changes: %s", message))
+}
+
+func executeStashList() {
+	entries, err := git.ListStashes()
+	if err != nil {
+		logger.Fatal("Failed to list stashes: %v", err)
+	}
+	if len(entries) == 0 {
+		ui.PrintMessage("No stash entries found.")
+		return
+	}
+
+	cfg := config.LoadConfigOrFatal()
+
+	relabeled := 0
+	rows := [][]string{{"Stash", "Message"}}
+	for i, entry := range entries {
+		if entry.IsAnonymous() {
+			diff, secrets := git.RedactSecrets(cfg, git.FilterGeneratedDiff(cfg, git.GetStashDiff(entry.Ref)))
+			if diff != "" {
+				ui.ReportSecrets(secrets, cfg.BlockOnSecrets)
+				label, err := ui.WithProgressSpinner(fmt.Sprintf("Summarizing %s...", entry.Ref), func(update func(string)) (string, error) {
+					return generateStashMessage(cfg, diff, "", func(status string) {
+						update(fmt.Sprintf("%s: %s with LLM...", entry.Ref, status))
+					})
+				})
+				if err != nil {
+					exitOnLLMError(err)
+				}
+				if label != "" {
+					entries[i].Message = fmt.Sprintf("On %s: %s", entry.Branch(), label)
+					relabeled++
+				}
+			}
+		}
+		rows = append(rows, []string{entry.Ref, entries[i].Message})
+	}
+
+	ui.DisplayTable(rows)
+
+	if relabeled == 0 {
+		return
+	}
+
+	if !autoApprove {
+		options := []string{"Save new labels", "Cancel"}
+		selectedOption, err := ui.PromptForSelection(options, "Save new labels", fmt.Sprintf("Relabel %d anonymous stashes?", relabeled))
+		if err != nil {
+			logger.Fatal("Error prompting for selection: %v", err)
+		}
+		if selectedOption != "Save new labels" {
+			return
+		}
+	}
+
+	if err := git.RewriteStashes(entries); err != nil {
+		logger.Fatal("Failed to relabel stashes: %v", err)
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Relabeled %d stashes successfully!", relabeled))
+}
+
+// exitOnLLMError reports a failed message generation and exits
+func exitOnLLMError(err error) {
+	if errors.Is(err, config.ErrLLMNotConfigured) {
+		ui.PrintFatal("LLM endpoint or API key not configured. Please run 'git ai config' to set up.")
+	}
+	logger.Fatal("Failed to generate stash message: %v", err)
+}
@@ -0,0 +1,36 @@
+package summary
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	since  string
+	until  string
+	author string
+	format string
+	repos  []string
+)
+
+// Cmd represents the summary command
+var Cmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Summarize recent work from the git log, e.g. for standup notes",
+	Long: `Gathers commits on all local branches across one or more repositories and writes a
+concise bullet summary grouped by repository and topic.
+
+Repositories are taken from --repo, then the summary_repos config setting, and default to
+the current repository.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		executeSummary()
+	},
+}
+
+func init() {
+	Cmd.Flags().StringVar(&since, "since", "yesterday", "Only include commits after this date (any date git understands)")
+	Cmd.Flags().StringVar(&until, "until", "", "Only include commits before this date")
+	Cmd.Flags().StringVar(&author, "author", "me", "Only include commits by this author; 'me' is your user.email, 'all' disables the filter")
+	Cmd.Flags().StringVar(&format, "format", "markdown", "Output format: markdown, slack or plain")
+	Cmd.Flags().StringSliceVar(&repos, "repo", nil, "Repository to include (can be repeated)")
+}
@@ -0,0 +1,48 @@
+package summary
+
+import (
+	"fmt"
+
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/llm"
+)
+
+// generateSummary generates a work summary for the commits of the given repositories
+func generateSummary(cfg config.Config, period string, repoCommits []llm.SummaryRepoData) (string, error) {
+	if cfg.APIKey == "" {
+		return "", config.ErrLLMNotConfigured
+	}
+
+	client, err := llm.NewClientWithProvider(cfg.Provider, cfg.Endpoint, cfg.APIKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	systemPrompt, err := llm.GetSummarySystemPrompt(format)
+	if err != nil {
+		return "", fmt.Errorf("failed to build system prompt: %w", err)
+	}
+
+	userPrompt, err := llm.GetSummaryUserPrompt(period, repoCommits)
+	if err != nil {
+		return "", fmt.Errorf("failed to build user prompt: %w", err)
+	}
+
+	messages := []llm.Message{
+		{
+			Role:    "system",
+			Content: systemPrompt,
+		},
+		{
+			Role:    "user",
+			Content: userPrompt,
+		},
+	}
+
+	response, err := client.ChatCompletion(cfg.Model, messages)
+	if err != nil {
+		return "", fmt.Errorf("failed to get completion: %w", err)
+	}
+
+	return llm.StripCodeFence(response), nil
+}
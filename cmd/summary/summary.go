@@ -0,0 +1,121 @@
+package summary
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/git"
+	"github.com/recrsn/git-ai/pkg/llm"
+	"github.com/recrsn/git-ai/pkg/logger"
+	"github.com/recrsn/git-ai/pkg/ui"
+)
+
+func executeSummary() {
+	cfg := config.LoadConfigOrFatal()
+
+	switch format {
+	case "markdown", "slack", "plain":
+	default:
+		ui.PrintFatalf("Invalid format %q, expected markdown, slack or plain", format)
+	}
+
+	var repoCommits []llm.SummaryRepoData
+	total := 0
+	for _, repo := range resolveRepos(cfg) {
+		authorFilter, err := resolveAuthor(repo)
+		if err != nil {
+			logger.Warn("Skipping %s: %v", repo, err)
+			continue
+		}
+
+		entries, err := git.GetRepoWorkLog(repo, since, until, authorFilter)
+		if err != nil {
+			logger.Warn("Skipping %s: %v", repo, err)
+			continue
+		}
+		if len(entries) == 0 {
+			continue
+		}
+
+		data := llm.SummaryRepoData{Name: filepath.Base(repo)}
+		for _, entry := range entries {
+			data.Commits = append(data.Commits, fmt.Sprintf("%s %s", entry.Date, entry.Subject()))
+		}
+		repoCommits = append(repoCommits, data)
+		total += len(entries)
+	}
+
+	if total == 0 {
+		ui.PrintMessagef("No commits found since %s.", since)
+		return
+	}
+
+	summary, err := ui.WithSpinnerResult(fmt.Sprintf("Summarizing %d commits with LLM...", total), func() (string, error) {
+		return generateSummary(cfg, describePeriod(), repoCommits)
+	})
+	if err != nil {
+		if errors.Is(err, config.ErrLLMNotConfigured) {
+			ui.PrintFatal("LLM endpoint or API key not configured. Please run 'git ai config' to set up.")
+		}
+		logger.Fatal("Failed to generate summary: %v", err)
+	}
+
+	ui.SetResult("summary", summary)
+	if !ui.IsJSONOutput() {
+		fmt.Println(summary)
+	}
+}
+
+// resolveRepos returns the absolute paths of the repositories to summarize
+func resolveRepos(cfg config.Config) []string {
+	paths := repos
+	if len(paths) == 0 {
+		paths = cfg.SummaryRepos
+	}
+	if len(paths) == 0 {
+		root, err := git.GetRepoRoot()
+		if err != nil {
+			ui.PrintFatal("Not in a git repository. Use --repo or the summary_repos setting to choose repositories.")
+		}
+		return []string{root}
+	}
+
+	var result []string
+	for _, path := range paths {
+		if strings.HasPrefix(path, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, path[2:])
+			}
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		result = append(result, path)
+	}
+	return result
+}
+
+// resolveAuthor turns the --author flag into a git log author filter for a repository
+func resolveAuthor(repo string) (string, error) {
+	switch author {
+	case "", "all":
+		return "", nil
+	case "me":
+		return git.GetRepoUserEmail(repo)
+	default:
+		return author, nil
+	}
+}
+
+// describePeriod describes the --since/--until range for the prompt
+func describePeriod() string {
+	period := "since " + since
+	if until != "" {
+		period += " until " + until
+	}
+	return period
+}
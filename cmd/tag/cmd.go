@@ -0,0 +1,32 @@
+package tag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	autoApprove    bool
+	bumpOverride   string
+	initialVersion string
+)
+
+// Cmd represents the tag command
+var Cmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Create the next semver release tag with generated release notes",
+	Long: `Inspects the commits since the last semver tag, infers the version bump (major for
+breaking changes, minor for features, patch otherwise), proposes the next version and
+creates an annotated tag whose message contains generated release notes.
+
+Conventional commits are classified locally; for other repositories the LLM decides the bump.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		executeTag()
+	},
+}
+
+func init() {
+	Cmd.Flags().BoolVar(&autoApprove, "auto", false, "Automatically create the tag without prompting")
+	Cmd.Flags().StringVar(&bumpOverride, "bump", "", "Force the version bump (major, minor or patch)")
+	Cmd.Flags().StringVar(&initialVersion, "initial-version", "v0.1.0", "Version to use when the repository has no semver tag yet")
+}
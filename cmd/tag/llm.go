@@ -0,0 +1,79 @@
+package tag
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/git"
+	"github.com/recrsn/git-ai/pkg/llm"
+)
+
+// classifyBump asks the LLM which version bump the commits call for
+func classifyBump(cfg config.Config, commitMessages []string) (git.BumpLevel, error) {
+	if cfg.APIKey == "" {
+		return git.BumpNone, config.ErrLLMNotConfigured
+	}
+
+	client, err := llm.NewClientWithProvider(cfg.Provider, cfg.Endpoint, cfg.APIKey)
+	if err != nil {
+		return git.BumpNone, fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	messages := []llm.Message{
+		{
+			Role:    "system",
+			Content: llm.GetTagBumpSystemPrompt(),
+		},
+		{
+			Role:    "user",
+			Content: "# Commits since the previous release:\n" + strings.Join(commitMessages, "\n---\n"),
+		},
+	}
+
+	response, err := client.ChatCompletion(cfg.Model, messages)
+	if err != nil {
+		return git.BumpNone, fmt.Errorf("failed to get completion: %w", err)
+	}
+
+	level, err := git.ParseBumpLevel(strings.Trim(strings.TrimSpace(response), "`.\"'"))
+	if err != nil {
+		return git.BumpNone, fmt.Errorf("unexpected bump classification: %w", err)
+	}
+	return level, nil
+}
+
+// generateReleaseNotes generates release notes for the given version from the commit messages
+func generateReleaseNotes(cfg config.Config, version, previousVersion string, commitMessages []string) (string, error) {
+	if cfg.APIKey == "" {
+		return "", config.ErrLLMNotConfigured
+	}
+
+	client, err := llm.NewClientWithProvider(cfg.Provider, cfg.Endpoint, cfg.APIKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	userPrompt, err := llm.GetTagUserPrompt(version, previousVersion, commitMessages)
+	if err != nil {
+		return "", fmt.Errorf("failed to build user prompt: %w", err)
+	}
+
+	messages := []llm.Message{
+		{
+			Role:    "system",
+			Content: llm.GetTagSystemPrompt(),
+		},
+		{
+			Role:    "user",
+			Content: userPrompt,
+		},
+	}
+
+	response, err := client.ChatCompletion(cfg.Model, messages)
+	if err != nil {
+		return "", fmt.Errorf("failed to get completion: %w", err)
+	}
+
+	return llm.StripCodeFence(response), nil
+}
@@ -0,0 +1,168 @@
+package tag
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/git"
+	"github.com/recrsn/git-ai/pkg/logger"
+	"github.com/recrsn/git-ai/pkg/ui"
+)
+
+func executeTag() {
+	cfg := config.LoadConfigOrFatal()
+
+	if !git.HasHead() {
+		ui.PrintFatal("The repository has no commits to tag yet.")
+	}
+
+	previousTag, previousVersion, err := git.GetLatestVersionTag()
+	if err != nil {
+		logger.Fatal("Failed to find the latest version tag: %v", err)
+	}
+
+	commitMessages, err := git.GetCommitMessagesInRange(previousTag, "HEAD")
+	if err != nil {
+		logger.Fatal("Failed to get commit messages: %v", err)
+	}
+	if len(commitMessages) == 0 {
+		ui.PrintMessagef("No commits since %s, nothing to release.", previousTag)
+		return
+	}
+
+	// Mask secrets before the messages are sent to the LLM
+	scanner := git.NewSecretScanner(cfg)
+	var secrets []git.SecretFinding
+	for i, message := range commitMessages {
+		var messageSecrets []git.SecretFinding
+		commitMessages[i], messageSecrets = scanner.RedactText(fmt.Sprintf("commit message %d", i+1), 0, message)
+		secrets = append(secrets, messageSecrets...)
+	}
+	ui.ReportSecrets(secrets, cfg.BlockOnSecrets)
+
+	var nextVersion git.Version
+	if previousTag == "" {
+		var ok bool
+		nextVersion, ok = git.ParseVersion(initialVersion)
+		if !ok {
+			ui.PrintFatalf("Invalid initial version %q", initialVersion)
+		}
+		ui.DisplayInfo(fmt.Sprintf("No semver tag found, proposing initial version %s", nextVersion))
+	} else {
+		level := resolveBump(cfg, commitMessages)
+		nextVersion = previousVersion.Bump(level)
+		ui.DisplayInfo(fmt.Sprintf("%d commits since %s, %s release", len(commitMessages), previousTag, level))
+	}
+
+	notes, err := ui.WithSpinnerResult("Generating release notes with LLM...", func() (string, error) {
+		return generateReleaseNotes(cfg, nextVersion.String(), previousTag, commitMessages)
+	})
+	if err != nil {
+		exitOnLLMError(err)
+	}
+
+	tagName := nextVersion.String()
+	if !autoApprove {
+		tagName, notes = reviewTag(tagName, notes)
+	}
+
+	if git.TagExists(tagName) {
+		ui.PrintFatalf("Tag %s already exists.", tagName)
+	}
+
+	ui.SetResult("tag", tagName)
+	ui.SetResult("notes", notes)
+
+	if err := git.CreateAnnotatedTag(tagName, notes); err != nil {
+		logger.Fatal("Failed to create tag: %v", err)
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Created tag %s. Push it with 'git push origin %s'.", tagName, tagName))
+}
+
+// resolveBump determines the version bump from --bump, conventional commits or the LLM
+func resolveBump(cfg config.Config, commitMessages []string) git.BumpLevel {
+	if bumpOverride != "" {
+		level, err := git.ParseBumpLevel(bumpOverride)
+		if err != nil {
+			ui.PrintFatalf("%v", err)
+		}
+		return level
+	}
+
+	if level, ok := inferBump(commitMessages); ok {
+		return level
+	}
+
+	level, err := ui.WithSpinnerResult("Classifying changes with LLM...", func() (git.BumpLevel, error) {
+		return classifyBump(cfg, commitMessages)
+	})
+	if err != nil {
+		if errors.Is(err, config.ErrLLMNotConfigured) {
+			exitOnLLMError(err)
+		}
+		logger.Warn("Failed to classify changes, assuming a patch release: %v", err)
+		return git.BumpPatch
+	}
+	return level
+}
+
+// inferBump returns the highest bump required by the conventional commits among the
+// messages. It returns false if none of the messages follow conventional commits.
+func inferBump(commitMessages []string) (git.BumpLevel, bool) {
+	level := git.BumpNone
+	conventional := false
+	for _, message := range commitMessages {
+		commitLevel, ok := git.ClassifyCommit(message)
+		conventional = conventional || ok
+		level = max(level, commitLevel)
+	}
+	return level, conventional
+}
+
+// reviewTag lets the user adjust the version and release notes before tagging
+func reviewTag(tagName, notes string) (string, string) {
+	for {
+		ui.DisplayBox(fmt.Sprintf("Release Notes for %s", tagName), notes)
+
+		options := []string{"Create tag", "Edit version", "Edit release notes", "Cancel"}
+		selectedOption, err := ui.PromptForSelection(options, "Create tag", "What would you like to do?")
+		if err != nil {
+			logger.Fatal("Error prompting for selection: %v", err)
+		}
+
+		switch selectedOption {
+		case "Create tag":
+			return tagName, notes
+		case "Edit version":
+			edited, err := ui.PromptForInput("Version:", tagName)
+			if err != nil {
+				logger.Fatal("Error prompting for input: %v", err)
+			}
+			if _, ok := git.ParseVersion(edited); !ok {
+				ui.PrintErrorf("%q is not a semantic version", edited)
+				continue
+			}
+			tagName = edited
+		case "Edit release notes":
+			edited, err := git.EditWithExternalEditor(notes)
+			if err != nil {
+				ui.PrintErrorf("Error editing release notes: %v", err)
+				continue
+			}
+			notes = edited
+		case "Cancel":
+			ui.PrintMessage("Tag cancelled.")
+			ui.Exit(0)
+		}
+	}
+}
+
+// exitOnLLMError reports a release notes or classification failure and exits
+func exitOnLLMError(err error) {
+	if errors.Is(err, config.ErrLLMNotConfigured) {
+		ui.PrintFatal("LLM endpoint or API key not configured. Please run 'git ai config' to set up.")
+	}
+	logger.Fatal("Failed to generate release notes: %v", err)
+}
@@ -2,17 +2,31 @@ package main
 
 import (
 	"github.com/recrsn/git-ai/cmd/branch"
+	"github.com/recrsn/git-ai/cmd/cache"
 	"github.com/recrsn/git-ai/cmd/commit"
 	cmdConfig "github.com/recrsn/git-ai/cmd/config"
+	"github.com/recrsn/git-ai/cmd/fixup"
+	"github.com/recrsn/git-ai/cmd/hook"
+	"github.com/recrsn/git-ai/cmd/resolve"
+	"github.com/recrsn/git-ai/cmd/reword"
+	"github.com/recrsn/git-ai/cmd/split"
+	"github.com/recrsn/git-ai/cmd/squash"
+	"github.com/recrsn/git-ai/cmd/stash"
+	"github.com/recrsn/git-ai/cmd/summary"
+	"github.com/recrsn/git-ai/cmd/tag"
 	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/llm"
 	"github.com/recrsn/git-ai/pkg/logger"
+	"github.com/recrsn/git-ai/pkg/ui"
 	"github.com/spf13/cobra"
 	"os"
 )
 
 var (
-	configPath string
-	verbose    int
+	configPath   string
+	verbose      int
+	outputFormat string
+	assumeYes    bool
 
 	rootCmd = &cobra.Command{
 		Use:   "git-ai",
@@ -37,10 +51,32 @@ var (
 					// Use log level from config
 					logger.SetLevelByName(cfg.LogLevel)
 				}
+
+				// All requests of this run share the configured rate limits
+				llm.SetRateLimits(cfg.RequestsPerMinute, cfg.TokensPerMinute)
+			}
+
+			ui.ConfigureTerminal()
+			if err := ui.SetOutputFormat(outputFormat); err != nil {
+				ui.ExitWithError(err.Error())
+			}
+			if assumeYes {
+				ui.SetNonInteractive()
+			}
+
+			// Prompts are disabled with --yes, so approve generated results automatically. JSON
+			// output disables prompts too, but only --yes or --auto approve anything.
+			if assumeYes {
+				if autoFlag := cmd.Flag("auto"); autoFlag != nil {
+					_ = autoFlag.Value.Set("true")
+				}
 			}
 
 			logger.Debug("Git AI session started")
 		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			ui.EmitResult()
+		},
 	}
 )
 
@@ -48,11 +84,24 @@ func init() {
 	// Add global flags
 	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Path to config file (default is $HOME/.git-ai.yaml and ./.git-ai.yaml)")
 	rootCmd.PersistentFlags().CountVarP(&verbose, "verbose", "v", "Enable verbose output (-v for info, -vv for debug)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", ui.OutputText, "Output format: text or json")
+	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "Never prompt; accept generated results as with --auto")
+	rootCmd.PersistentFlags().BoolVar(&assumeYes, "non-interactive", false, "Alias for --yes")
 
 	// Add subcommands
 	rootCmd.AddCommand(branch.Cmd)
+	rootCmd.AddCommand(cache.Cmd)
 	rootCmd.AddCommand(commit.Cmd)
 	rootCmd.AddCommand(cmdConfig.Cmd)
+	rootCmd.AddCommand(fixup.Cmd)
+	rootCmd.AddCommand(hook.Cmd)
+	rootCmd.AddCommand(resolve.Cmd)
+	rootCmd.AddCommand(reword.Cmd)
+	rootCmd.AddCommand(split.Cmd)
+	rootCmd.AddCommand(squash.Cmd)
+	rootCmd.AddCommand(stash.Cmd)
+	rootCmd.AddCommand(summary.Cmd)
+	rootCmd.AddCommand(tag.Cmd)
 }
 
 func main() {
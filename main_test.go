@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/recrsn/git-ai/pkg/llm"
+)
+
+// runMainEnv makes the test binary run git-ai instead of the tests, so that commands can
+// be run in a separate process like the real binary
+const runMainEnv = "GIT_AI_TEST_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// jsonTestRepo creates a repository with one commit and a config pointing at a fake LLM
+func jsonTestRepo(t *testing.T) (dir, configPath string) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(llm.OpenAIResponse{
+			Choices: []llm.OpenAIChoice{{Message: llm.OpenAIMessage{Role: "assistant", Content: "Add greeting"}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	dir = t.TempDir()
+	configPath = filepath.Join(t.TempDir(), "config.yaml")
+	config := "provider: openai\napi_key: test\nmodel: test\nendpoint: " + server.URL + "\n"
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runGit(t, dir, "init", "-q")
+	runGit(t, dir, "config", "user.name", "Test")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	runGit(t, dir, "config", "commit.gpgsign", "false")
+	writeFile(t, dir, "main.go", "package main\n")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "Initial commit")
+	return dir, configPath
+}
+
+// runGit runs a git command in dir, failing the test on errors
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+}
+
+// writeFile writes a file in dir
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJSONOutputPrintsOneObject(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, dir string)
+		args  []string
+		// errors is whether the result is expected to be an error
+		errors bool
+	}{
+		{
+			name:  "commit dry run",
+			setup: stageGreeting,
+			args:  []string{"commit", "--dry-run"},
+		},
+		{
+			name:   "commit without confirmation",
+			setup:  stageGreeting,
+			args:   []string{"commit"},
+			errors: true,
+		},
+		{
+			name:  "commit with --yes",
+			setup: stageGreeting,
+			args:  []string{"commit", "--yes"},
+		},
+		{
+			name:  "split with a single hunk",
+			setup: stageGreeting,
+			args:  []string{"split"},
+		},
+		{
+			name: "stash without changes",
+			args: []string{"stash"},
+		},
+		{
+			name: "stash list without stashes",
+			args: []string{"stash", "list"},
+		},
+		{
+			name:  "branch dry run",
+			setup: stageGreeting,
+			args:  []string{"branch", "--dry-run", "add a greeting"},
+		},
+		{
+			name: "cache clear",
+			args: []string{"cache", "clear"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, configPath := jsonTestRepo(t)
+			if tt.setup != nil {
+				tt.setup(t, dir)
+			}
+
+			cmd := exec.Command(os.Args[0], append([]string{"--config", configPath, "--output", "json"}, tt.args...)...)
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(), runMainEnv+"=1", "HOME="+t.TempDir())
+			var stdout, stderr bytes.Buffer
+			cmd.Stdout = &stdout
+			cmd.Stderr = &stderr
+			err := cmd.Run()
+			if tt.errors != (err != nil) {
+				t.Errorf("Unexpected exit status %v\nstderr: %s", err, stderr.String())
+			}
+
+			decoder := json.NewDecoder(&stdout)
+			var result map[string]any
+			if err := decoder.Decode(&result); err != nil {
+				t.Fatalf("Expected a JSON object on stdout: %v\nstderr: %s", err, stderr.String())
+			}
+			if _, hasError := result["error"]; hasError != tt.errors {
+				t.Errorf("Unexpected error field in %v", result)
+			}
+			var extra any
+			if err := decoder.Decode(&extra); !errors.Is(err, io.EOF) {
+				t.Errorf("Expected exactly one JSON object, got more: %v", extra)
+			}
+		})
+	}
+}
+
+// stageGreeting stages a single change to main.go
+func stageGreeting(t *testing.T, dir string) {
+	writeFile(t, dir, "main.go", "package main\n\nfunc main() { println(\"hello\") }\n")
+	runGit(t, dir, "add", "main.go")
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/recrsn/git-ai/pkg/logger"
 	"github.com/spf13/viper"
@@ -24,6 +25,30 @@ type Config struct {
 	Endpoint string `mapstructure:"endpoint"`
 	Editor   string `mapstructure:"editor"`
 	LogLevel string `mapstructure:"log_level"`
+
+	// SummaryRepos lists the repositories 'git ai summary' collects commits from
+	SummaryRepos []string `mapstructure:"summary_repos"`
+
+	// SecretPatterns are additional regular expressions for secrets to mask before prompting
+	SecretPatterns []string `mapstructure:"secret_patterns"`
+	// BlockOnSecrets stops instead of masking when secrets are found in the changes
+	BlockOnSecrets bool `mapstructure:"block_on_secrets"`
+
+	// ExcludePaths are gitignore-style patterns for files never sent to the LLM, in addition to .git-ai-ignore
+	ExcludePaths []string `mapstructure:"exclude_paths"`
+	// MentionExcludedPaths keeps excluded files in the list of changed files, without their contents
+	MentionExcludedPaths bool `mapstructure:"mention_excluded_paths"`
+
+	// SummaryCacheTTL is how long summaries of large diffs are cached, zero disables the cache
+	SummaryCacheTTL time.Duration `mapstructure:"summary_cache_ttl"`
+	// SummaryCacheMaxSize is the size in bytes the summary cache is pruned to
+	SummaryCacheMaxSize int64 `mapstructure:"summary_cache_max_size"`
+
+	// SummaryConcurrency sets the number of summary requests sent at the same time, by provider
+	SummaryConcurrency map[string]int `mapstructure:"summary_concurrency"`
+	// RequestsPerMinute and TokensPerMinute limit all LLM requests, zero means no limit
+	RequestsPerMinute int `mapstructure:"requests_per_minute"`
+	TokensPerMinute   int `mapstructure:"tokens_per_minute"`
 }
 
 // DefaultConfig returns the default configuration
@@ -35,6 +60,9 @@ func DefaultConfig() Config {
 		Endpoint: "https://api.openai.com/v1",
 		Editor:   "",
 		LogLevel: "info",
+
+		SummaryCacheTTL:     7 * 24 * time.Hour,
+		SummaryCacheMaxSize: 50 << 20,
 	}
 }
 
@@ -52,6 +80,8 @@ func initViper() (*viper.Viper, error) {
 		v.SetDefault("api_key", defaults.APIKey)
 		v.SetDefault("model", defaults.Model)
 		v.SetDefault("log_level", defaults.LogLevel)
+		v.SetDefault("summary_cache_ttl", defaults.SummaryCacheTTL)
+		v.SetDefault("summary_cache_max_size", defaults.SummaryCacheMaxSize)
 
 		// Read configuration
 		if err := v.ReadInConfig(); err != nil {
@@ -90,6 +120,8 @@ func initViper() (*viper.Viper, error) {
 	v.SetDefault("model", defaults.Model)
 	v.SetDefault("editor", defaults.Editor)
 	v.SetDefault("log_level", defaults.LogLevel)
+	v.SetDefault("summary_cache_ttl", defaults.SummaryCacheTTL)
+	v.SetDefault("summary_cache_max_size", defaults.SummaryCacheMaxSize)
 
 	// Environment variables
 	v.SetEnvPrefix("GIT_AI")
@@ -195,6 +227,37 @@ func SaveConfig(config Config) error {
 	v.Set("endpoint", config.Endpoint)
 	v.Set("editor", config.Editor)
 	v.Set("log_level", config.LogLevel)
+	if len(config.SummaryRepos) > 0 {
+		v.Set("summary_repos", config.SummaryRepos)
+	}
+	if len(config.SecretPatterns) > 0 {
+		v.Set("secret_patterns", config.SecretPatterns)
+	}
+	if config.BlockOnSecrets {
+		v.Set("block_on_secrets", true)
+	}
+	if len(config.ExcludePaths) > 0 {
+		v.Set("exclude_paths", config.ExcludePaths)
+	}
+	if config.MentionExcludedPaths {
+		v.Set("mention_excluded_paths", true)
+	}
+	defaults := DefaultConfig()
+	if config.SummaryCacheTTL != defaults.SummaryCacheTTL {
+		v.Set("summary_cache_ttl", config.SummaryCacheTTL.String())
+	}
+	if config.SummaryCacheMaxSize != defaults.SummaryCacheMaxSize {
+		v.Set("summary_cache_max_size", config.SummaryCacheMaxSize)
+	}
+	if len(config.SummaryConcurrency) > 0 {
+		v.Set("summary_concurrency", config.SummaryConcurrency)
+	}
+	if config.RequestsPerMinute > 0 {
+		v.Set("requests_per_minute", config.RequestsPerMinute)
+	}
+	if config.TokensPerMinute > 0 {
+		v.Set("tokens_per_minute", config.TokensPerMinute)
+	}
 
 	if err := v.WriteConfig(); err != nil {
 		// Check if the file doesn't exist
@@ -0,0 +1,119 @@
+package git
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+)
+
+// linguistAttributeNames are the .gitattributes entries that override the generated file heuristics
+var linguistAttributeNames = []string{"linguist-generated", "linguist-vendored", "linguist-documentation"}
+
+// attributeState is the value of a boolean git attribute for a path
+type attributeState int
+
+const (
+	attributeUnspecified attributeState = iota
+	attributeSet
+	attributeUnset
+)
+
+// parseAttributeState interprets the value git check-attr reports for a boolean attribute
+func parseAttributeState(value string) attributeState {
+	switch strings.ToLower(value) {
+	case "set", "true", "1":
+		return attributeSet
+	case "unset", "false", "0":
+		return attributeUnset
+	default:
+		return attributeUnspecified
+	}
+}
+
+// linguistAttributes holds the linguist attributes set for a path in .gitattributes
+type linguistAttributes struct {
+	generated     attributeState
+	vendored      attributeState
+	documentation attributeState
+}
+
+// override reports whether the attributes decide if a path is filtered. Any attribute that is
+// set filters the path; otherwise an explicitly unset attribute keeps it, e.g.
+// "docs/** -linguist-documentation" for hand-written documentation.
+func (a linguistAttributes) override() (filtered bool, ok bool) {
+	states := []attributeState{a.generated, a.vendored, a.documentation}
+	for _, state := range states {
+		if state == attributeSet {
+			return true, true
+		}
+	}
+	for _, state := range states {
+		if state == attributeUnset {
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// getLinguistAttributes looks up the linguist attributes of the given paths with a single
+// git check-attr call. Paths are relative to the repository root, as in diffs. With cached,
+// the .gitattributes files are read from the index instead of the working tree. Paths
+// without any of the attributes are left out of the result.
+func getLinguistAttributes(paths []string, cached bool) (map[string]linguistAttributes, error) {
+	var input strings.Builder
+	for _, path := range paths {
+		if path != "" {
+			input.WriteString(path + "\x00")
+		}
+	}
+	if input.Len() == 0 {
+		return nil, nil
+	}
+
+	// check-attr resolves paths against the current directory, so run it from the root
+	root, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"check-attr", "-z", "--stdin"}
+	if cached {
+		args = append(args, "--cached")
+	}
+	args = append(args, linguistAttributeNames...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = strings.TrimSpace(string(root))
+	cmd.Stdin = strings.NewReader(input.String())
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	return parseCheckAttrOutput(out.String()), nil
+}
+
+// parseCheckAttrOutput parses the NUL-separated "<path> <attribute> <value>" triples
+// written by git check-attr -z
+func parseCheckAttrOutput(output string) map[string]linguistAttributes {
+	attributes := make(map[string]linguistAttributes)
+	fields := strings.Split(output, "\x00")
+	for i := 0; i+2 < len(fields); i += 3 {
+		path, name, state := fields[i], fields[i+1], parseAttributeState(fields[i+2])
+		if state == attributeUnspecified {
+			continue
+		}
+
+		attrs := attributes[path]
+		switch name {
+		case "linguist-generated":
+			attrs.generated = state
+		case "linguist-vendored":
+			attrs.vendored = state
+		case "linguist-documentation":
+			attrs.documentation = state
+		}
+		attributes[path] = attrs
+	}
+	return attributes
+}
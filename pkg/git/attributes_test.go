@@ -0,0 +1,129 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// initTestRepo creates a git repository in a temporary directory and returns its path
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	runTestGit(t, dir, "init", "-q")
+	runTestGit(t, dir, "config", "user.name", "Test")
+	runTestGit(t, dir, "config", "user.email", "test@example.com")
+	runTestGit(t, dir, "config", "commit.gpgsign", "false")
+	return dir
+}
+
+// runTestGit runs a git command in dir and returns its output, failing the test on errors
+func runTestGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+	return string(out)
+}
+
+// writeTestFile writes a file below dir, creating its parent directories
+func writeTestFile(t *testing.T, dir, path, content string) {
+	t.Helper()
+	path = filepath.Join(dir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseCheckAttrOutput(t *testing.T) {
+	output := "db/query.sqlc.go\x00linguist-generated\x00set\x00" +
+		"db/query.sqlc.go\x00linguist-vendored\x00unspecified\x00" +
+		"docs/guide.md\x00linguist-documentation\x00false\x00" +
+		"lib/copy.js\x00linguist-vendored\x00true\x00" +
+		"main.go\x00linguist-generated\x00unspecified\x00"
+
+	attributes := parseCheckAttrOutput(output)
+
+	if attributes["db/query.sqlc.go"].generated != attributeSet {
+		t.Errorf("Expected db/query.sqlc.go to be marked as generated")
+	}
+	if attributes["docs/guide.md"].documentation != attributeUnset {
+		t.Errorf("Expected docs/guide.md to be marked as not documentation")
+	}
+	if attributes["lib/copy.js"].vendored != attributeSet {
+		t.Errorf("Expected lib/copy.js to be marked as vendored")
+	}
+	if _, ok := attributes["main.go"]; ok {
+		t.Errorf("Expected paths without attributes to be left out")
+	}
+}
+
+func TestIsGeneratedWithAttributes(t *testing.T) {
+	detector := NewGeneratedFileDetector()
+	detector.attributes = parseCheckAttrOutput(
+		"db/query.sqlc.go\x00linguist-generated\x00set\x00" +
+			"docs/guide.md\x00linguist-documentation\x00unset\x00" +
+			"vendor/patched/lib.go\x00linguist-vendored\x00false\x00" +
+			"app.min.js\x00linguist-generated\x00false\x00" +
+			"app.min.js\x00linguist-vendored\x00set\x00",
+	)
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		// Attributes mark files the heuristics miss
+		{"db/query.sqlc.go", true},
+		// Unset attributes keep files the heuristics would filter
+		{"docs/guide.md", false},
+		{"vendor/patched/lib.go", false},
+		// A set attribute wins over an unset one
+		{"app.min.js", true},
+		// Paths without attributes fall back to the heuristics
+		{"docs/other.md", true},
+		{"main.go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if result := detector.IsGenerated(tt.path); result != tt.expected {
+				t.Errorf("IsGenerated(%q) = %v, want %v", tt.path, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetLinguistAttributesFromSubdirectory(t *testing.T) {
+	dir := initTestRepo(t)
+	writeTestFile(t, dir, ".gitattributes", "docs/** -linguist-documentation\n")
+	writeTestFile(t, dir, "docs/a.md", "# A\n")
+	writeTestFile(t, dir, "sub/main.go", "package main\n")
+	runTestGit(t, dir, "add", "-A")
+
+	// Paths from diffs are relative to the root, wherever git ai runs from
+	t.Chdir(filepath.Join(dir, "sub"))
+	attributes, err := getLinguistAttributes([]string{"docs/a.md"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attributes["docs/a.md"].documentation != attributeUnset {
+		t.Errorf("Expected docs/a.md to be marked as not documentation, got %+v", attributes)
+	}
+
+	// With cached, the staged .gitattributes applies rather than the working tree's
+	writeTestFile(t, dir, ".gitattributes", "docs/** linguist-documentation\n")
+	attributes, err = getLinguistAttributes([]string{"docs/a.md"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attributes["docs/a.md"].documentation != attributeUnset {
+		t.Errorf("Expected the staged .gitattributes to be read, got %+v", attributes)
+	}
+}
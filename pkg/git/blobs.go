@@ -0,0 +1,119 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// maxBlobSample is how much of each blob is kept for content-based detection
+const maxBlobSample = 1024 * 1024
+
+// isNullBlobID reports whether a blob id is git's all-zero id for a missing side of a diff
+func isNullBlobID(id string) bool {
+	return id == "" || strings.Trim(id, "0") == ""
+}
+
+// getIndexBlobIDs returns the blob ids of the given repository-relative paths in the index.
+// Paths that are not in the index are left out.
+func getIndexBlobIDs(paths []string) (map[string]string, error) {
+	args := []string{"ls-files", "--stage", "-z", "--full-name", "--"}
+	for _, path := range paths {
+		if path != "" {
+			args = append(args, ":(top,literal)"+path)
+		}
+	}
+	if len(args) == 5 {
+		return nil, nil
+	}
+
+	cmd := exec.Command("git", args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("error listing index entries: %v", err)
+	}
+
+	ids := make(map[string]string)
+	for _, entry := range strings.Split(out.String(), "\x00") {
+		// "<mode> <object> <stage>\t<path>"
+		info, path, ok := strings.Cut(entry, "\t")
+		fields := strings.Fields(info)
+		if !ok || len(fields) != 3 || !strings.HasPrefix(fields[0], "100") {
+			continue
+		}
+		// Only keep stage 0, or "ours" for conflicted paths
+		if fields[2] == "0" || (fields[2] == "2" && ids[path] == "") {
+			ids[path] = fields[1]
+		}
+	}
+	return ids, nil
+}
+
+// readBlobs reads the start of several blobs with a single git cat-file --batch process.
+// The result is keyed by the requested ids; missing objects are left out.
+func readBlobs(ids []string) (map[string][]byte, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	cmd := exec.Command("git", "cat-file", "--batch")
+	cmd.Stdin = strings.NewReader(strings.Join(ids, "\n") + "\n")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error starting git cat-file: %v", err)
+	}
+
+	blobs, parseErr := parseCatFileBatch(bufio.NewReader(stdout), ids)
+	if parseErr != nil {
+		// Drain the rest of the output so the process can exit
+		_, _ = io.Copy(io.Discard, stdout)
+	}
+	if err := cmd.Wait(); err != nil && parseErr == nil {
+		return nil, fmt.Errorf("error reading blobs: %v", err)
+	}
+	return blobs, parseErr
+}
+
+// parseCatFileBatch parses git cat-file --batch output, which has one
+// "<object> <type> <size>\n<contents>\n" entry or "<id> missing\n" line per requested id
+func parseCatFileBatch(r *bufio.Reader, ids []string) (map[string][]byte, error) {
+	blobs := make(map[string][]byte, len(ids))
+	for _, id := range ids {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return blobs, fmt.Errorf("unexpected end of git cat-file output: %v", err)
+		}
+
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			// "<id> missing" or "<id> ambiguous"
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return blobs, fmt.Errorf("invalid git cat-file header %q", strings.TrimSpace(header))
+		}
+
+		sample := make([]byte, min(size, maxBlobSample))
+		if _, err := io.ReadFull(r, sample); err != nil {
+			return blobs, fmt.Errorf("error reading object %s: %v", fields[0], err)
+		}
+		// Skip the rest of the contents and the trailing newline
+		if _, err := r.Discard(int(size-int64(len(sample))) + 1); err != nil {
+			return blobs, fmt.Errorf("error reading object %s: %v", fields[0], err)
+		}
+
+		if fields[1] == "blob" {
+			blobs[id] = sample
+		}
+	}
+	return blobs, nil
+}
@@ -0,0 +1,58 @@
+package git
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseCatFileBatch(t *testing.T) {
+	large := strings.Repeat("x", maxBlobSample+10)
+	output := "1111111111111111111111111111111111111111 blob 12\npackage main\n" +
+		"2222222 missing\n" +
+		"3333333333333333333333333333333333333333 blob " + strconv.Itoa(len(large)) + "\n" + large + "\n" +
+		"4444444444444444444444444444444444444444 blob 0\n\n"
+
+	ids := []string{"1111111111111111111111111111111111111111", "2222222", "3333333", "4444444"}
+	blobs, err := parseCatFileBatch(bufio.NewReader(strings.NewReader(output)), ids)
+	if err != nil {
+		t.Fatalf("parseCatFileBatch() error = %v", err)
+	}
+
+	if string(blobs[ids[0]]) != "package main" {
+		t.Errorf("Expected first blob to be read, got %q", blobs[ids[0]])
+	}
+	if _, ok := blobs["2222222"]; ok {
+		t.Errorf("Expected missing objects to be left out")
+	}
+	if len(blobs["3333333"]) != maxBlobSample {
+		t.Errorf("Expected large blobs to be cut to %d bytes, got %d", maxBlobSample, len(blobs["3333333"]))
+	}
+	if data, ok := blobs["4444444"]; !ok || len(data) != 0 {
+		t.Errorf("Expected empty blob to be read, got %q", data)
+	}
+
+	if _, err := parseCatFileBatch(bufio.NewReader(strings.NewReader(output[:20])), ids); err == nil {
+		t.Errorf("Expected an error for truncated output")
+	}
+}
+
+func TestAnalyzeContent(t *testing.T) {
+	detector := NewGeneratedFileDetector()
+
+	traits := detector.analyzeContent([]byte("// Code generated by sqlc. DO NOT EDIT.\npackage db\n"))
+	if !traits.generatedHeader || traits.minified {
+		t.Errorf("Expected generated header to be detected, got %+v", traits)
+	}
+
+	traits = detector.analyzeContent([]byte(strings.Repeat("var a=1;", 200) + "\n"))
+	if traits.generatedHeader || !traits.minified {
+		t.Errorf("Expected minified content to be detected, got %+v", traits)
+	}
+
+	traits = detector.analyzeContent([]byte("package main\n\nfunc main() {}\n"))
+	if traits.generatedHeader || traits.minified {
+		t.Errorf("Expected regular source to pass, got %+v", traits)
+	}
+}
@@ -0,0 +1,203 @@
+package git
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/llm"
+	"github.com/recrsn/git-ai/pkg/logger"
+)
+
+// summaryCacheVersion is part of every cache key and changes when the format of cached
+// summaries changes
+const summaryCacheVersion = 1
+
+// summaryCachePath is the location of the summary cache inside the git directory
+const summaryCachePath = "git-ai/cache"
+
+// SummaryCache stores the summaries of file diffs on disk, so that summarizing the same
+// changes again, e.g. after rejecting a commit message, needs no LLM calls. A nil cache
+// is valid and never stores anything.
+type SummaryCache struct {
+	dir     string
+	ttl     time.Duration
+	maxSize int64
+}
+
+// OpenSummaryCache returns the summary cache of the current repository, or nil when the
+// cache is disabled with a zero summary_cache_ttl or the git directory can't be found
+func OpenSummaryCache(cfg config.Config) *SummaryCache {
+	if cfg.SummaryCacheTTL <= 0 {
+		return nil
+	}
+
+	dir, err := GetSummaryCacheDir()
+	if err != nil {
+		logger.Debug("Summary cache disabled: %v", err)
+		return nil
+	}
+	return &SummaryCache{dir: dir, ttl: cfg.SummaryCacheTTL, maxSize: cfg.SummaryCacheMaxSize}
+}
+
+// GetSummaryCacheDir returns the absolute path of the summary cache directory, inside the
+// common git directory so that all worktrees share it
+func GetSummaryCacheDir() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-common-dir")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("error getting git directory: %v", err)
+	}
+
+	gitDir, err := filepath.Abs(strings.TrimSpace(out.String()))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, filepath.FromSlash(summaryCachePath)), nil
+}
+
+// ClearSummaryCache removes all cached summaries and returns how many there were
+func ClearSummaryCache() (int, error) {
+	dir, err := GetSummaryCacheDir()
+	if err != nil {
+		return 0, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("failed to read summary cache: %w", err)
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return 0, fmt.Errorf("failed to remove summary cache: %w", err)
+	}
+	return len(entries), nil
+}
+
+// summaryCacheKey identifies the summary of a file diff by its contents, the model that
+// summarizes it and the prompt it is summarized with
+func summaryCacheKey(cfg config.Config, fileDiff FileDiff) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d\x00%s\x00%s\x00", summaryCacheVersion, cfg.Provider, cfg.Model)
+	promptHash := sha256.Sum256([]byte(llm.GetDiffSummarySystemPrompt()))
+	hash.Write(promptHash[:])
+	hash.Write([]byte(fileDiff.PromptContent()))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Get returns the cached summary for key. An empty summary means the changes were minor.
+// Entries older than the TTL are removed instead of returned.
+func (c *SummaryCache) Get(key string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	path := filepath.Join(c.dir, key)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false
+	}
+	if time.Since(info.ModTime()) > c.ttl {
+		os.Remove(path)
+		return "", false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// Put stores the summary for key. Failures only disable caching for this entry.
+func (c *SummaryCache) Put(key, summary string) {
+	if c == nil {
+		return
+	}
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		logger.Debug("Failed to create summary cache: %v", err)
+		return
+	}
+
+	// Write to a temporary file first so that concurrent readers never see partial entries
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		logger.Debug("Failed to write summary cache entry: %v", err)
+		return
+	}
+	_, writeErr := tmp.WriteString(summary)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil {
+		os.Remove(tmp.Name())
+		logger.Debug("Failed to write summary cache entry: %v", errors.Join(writeErr, closeErr))
+		return
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(c.dir, key)); err != nil {
+		os.Remove(tmp.Name())
+		logger.Debug("Failed to write summary cache entry: %v", err)
+	}
+}
+
+// Prune removes expired entries and then the oldest entries until the cache is no
+// larger than its maximum size
+func (c *SummaryCache) Prune() {
+	if c == nil {
+		return
+	}
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+
+	type cacheEntry struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var kept []cacheEntry
+	var total int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(c.dir, entry.Name())
+		if time.Since(info.ModTime()) > c.ttl {
+			os.Remove(path)
+			continue
+		}
+		kept = append(kept, cacheEntry{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+
+	if c.maxSize <= 0 || total <= c.maxSize {
+		return
+	}
+
+	sort.Slice(kept, func(i, j int) bool {
+		return kept[i].modTime.Before(kept[j].modTime)
+	})
+	for _, entry := range kept {
+		if total <= c.maxSize {
+			break
+		}
+		if err := os.Remove(entry.path); err == nil {
+			total -= entry.size
+		}
+	}
+}
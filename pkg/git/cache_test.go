@@ -0,0 +1,110 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/recrsn/git-ai/pkg/config"
+)
+
+func TestSummaryCache(t *testing.T) {
+	cache := &SummaryCache{dir: filepath.Join(t.TempDir(), "cache"), ttl: time.Hour, maxSize: 10}
+
+	if _, ok := cache.Get("missing"); ok {
+		t.Errorf("Expected a miss for a missing entry")
+	}
+
+	cache.Put("a", "a.go: Added retries")
+	cache.Put("minor", "")
+	if summary, ok := cache.Get("a"); !ok || summary != "a.go: Added retries" {
+		t.Errorf("Get() = %q, %v, want the stored summary", summary, ok)
+	}
+	if summary, ok := cache.Get("minor"); !ok || summary != "" {
+		t.Errorf("Expected an empty summary to be cached for minor changes, got %q, %v", summary, ok)
+	}
+
+	// Expired entries are not returned
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(cache.dir, "a"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("Expected expired entries to be missed")
+	}
+
+	var nilCache *SummaryCache
+	nilCache.Put("a", "summary")
+	if _, ok := nilCache.Get("a"); ok {
+		t.Errorf("Expected a nil cache to never hit")
+	}
+}
+
+func TestSummaryCachePrune(t *testing.T) {
+	cache := &SummaryCache{dir: t.TempDir(), ttl: time.Hour, maxSize: 10}
+
+	for i, name := range []string{"oldest", "older", "newest"} {
+		cache.Put(name, "123456")
+		modTime := time.Now().Add(time.Duration(i-3) * time.Minute)
+		if err := os.Chtimes(filepath.Join(cache.dir, name), modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expired := time.Now().Add(-2 * time.Hour)
+	cache.Put("expired", "")
+	if err := os.Chtimes(filepath.Join(cache.dir, "expired"), expired, expired); err != nil {
+		t.Fatal(err)
+	}
+
+	cache.Prune()
+
+	entries, _ := os.ReadDir(cache.dir)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if strings.Join(names, ",") != "newest" {
+		t.Errorf("Expected only the newest entry to be kept, got %v", names)
+	}
+}
+
+func TestCacheBatchSummary(t *testing.T) {
+	cfg := config.Config{Provider: "openai", Model: "test"}
+	files := ParseDiff(`diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1 +1 @@
+-a
++b
+diff --git a/b.go b/b.go
+--- a/b.go
++++ b/b.go
+@@ -1 +1 @@
+-a
++ b
+`).Files
+
+	cache := &SummaryCache{dir: t.TempDir(), ttl: time.Hour}
+	cacheBatchSummary(cfg, cache, files, "```\na.go: Changed a to b\n```")
+	if summary, ok := cache.Get(summaryCacheKey(cfg, files[0])); !ok || summary != "a.go: Changed a to b" {
+		t.Errorf("Expected the line of a.go to be cached, got %q, %v", summary, ok)
+	}
+	if summary, ok := cache.Get(summaryCacheKey(cfg, files[1])); !ok || summary != "" {
+		t.Errorf("Expected b.go to be cached as minor, got %q, %v", summary, ok)
+	}
+
+	// Lines that can't be attributed keep the whole batch out of the cache
+	cache = &SummaryCache{dir: t.TempDir(), ttl: time.Hour}
+	cacheBatchSummary(cfg, cache, files, "a.go, b.go: Changed both")
+	if _, ok := cache.Get(summaryCacheKey(cfg, files[0])); ok {
+		t.Errorf("Expected nothing to be cached for a shared line")
+	}
+
+	other := cfg
+	other.Model = "other"
+	if summaryCacheKey(cfg, files[0]) == summaryCacheKey(other, files[0]) {
+		t.Errorf("Expected the model to be part of the cache key")
+	}
+}
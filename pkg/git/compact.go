@@ -0,0 +1,440 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// compactContextLines is the number of context lines kept around changes, like git diff -U1
+	compactContextLines = 1
+	// largeDeletionLines is the number of removed lines above which a deleted file or a
+	// hunk that only removes lines is collapsed to its line count
+	largeDeletionLines = 20
+	// largeDataFileLines is the number of changed lines above which a data file, like a
+	// JSON fixture or snapshot, is collapsed to a stat line
+	largeDataFileLines = 100
+	// maxRankedOmission is the share of changed lines that ranking may leave out before
+	// the diff is considered too large and summarized instead
+	maxRankedOmission = 0.5
+)
+
+// dataFileExtensions are file types whose large changes are data rather than logic
+var dataFileExtensions = map[string]bool{
+	".json": true, ".csv": true, ".tsv": true, ".svg": true, ".xml": true,
+	".yaml": true, ".yml": true, ".snap": true, ".txt": true,
+}
+
+// docFileExtensions are documentation file types, which rank below code
+var docFileExtensions = map[string]bool{
+	".md": true, ".rst": true, ".adoc": true, ".txt": true,
+}
+
+// declarationRegex matches changed lines that declare functions, types and the like
+var declarationRegex = regexp.MustCompile(`^\s*(?:export\s+|pub\s+|public\s+|private\s+|protected\s+|static\s+|async\s+)*(?:func|fn|def|class|type|interface|struct|enum|trait|impl|module|function)\b`)
+
+// compactHunk is a hunk of a compacted file diff
+type compactHunk struct {
+	Hunk
+	// collapsed is set for large deletions, which are sent as their line count only
+	collapsed bool
+	// omitted is set for hunks that ranking left out to fit the budget
+	omitted bool
+}
+
+// compactFile is a file diff being compacted
+type compactFile struct {
+	file  FileDiff
+	hunks []compactHunk
+	// stat is the reason the whole file is sent as a stat line, e.g. "deleted"
+	stat string
+}
+
+// CompactDiff shrinks a diff that exceeds tokenLimit without calling the LLM. It reduces
+// the context to one line around changes, drops hunks that only change whitespace,
+// collapses large deletions and lockfile-like files to stats and, as a last step, leaves
+// out the least important hunks. Each step is only applied while the diff is still too
+// large. Returns the compacted diff and whether it fits within tokenLimit; when it does
+// not, the diff is returned without the ranking step so that it can be summarized.
+func CompactDiff(diff string, tokenLimit int) (string, bool) {
+	if EstimateTokens(diff) <= tokenLimit {
+		return diff, true
+	}
+
+	parsed := ParseDiff(diff)
+	if len(parsed.Files) == 0 {
+		return diff, false
+	}
+
+	files := make([]*compactFile, len(parsed.Files))
+	for i, file := range parsed.Files {
+		files[i] = &compactFile{file: file}
+		for _, hunk := range file.Hunks {
+			files[i].hunks = append(files[i].hunks, reduceHunkContext(hunk, compactContextLines)...)
+		}
+	}
+
+	steps := []func([]*compactFile){dropWhitespaceHunks, collapseLargeChanges}
+	result := renderCompactDiff(parsed.Preamble, files)
+	for _, step := range steps {
+		if EstimateTokens(result) <= tokenLimit {
+			return result, true
+		}
+		step(files)
+		result = renderCompactDiff(parsed.Preamble, files)
+	}
+	if EstimateTokens(result) <= tokenLimit {
+		return result, true
+	}
+
+	if ranked, ok := rankHunks(parsed.Preamble, files, tokenLimit); ok {
+		return ranked, true
+	}
+	return result, false
+}
+
+// reduceHunkContext keeps only the given number of context lines around changes, splitting
+// the hunk where the changes are further apart, as git diff -U<context> would
+func reduceHunkContext(hunk Hunk, context int) []compactHunk {
+	keep := make([]bool, len(hunk.Lines))
+	changed := false
+	for i, line := range hunk.Lines {
+		if line.Kind != LineAdded && line.Kind != LineRemoved {
+			continue
+		}
+		changed = true
+		for j := max(0, i-context); j <= min(len(hunk.Lines)-1, i+context); j++ {
+			keep[j] = true
+		}
+	}
+	if !changed {
+		return []compactHunk{{Hunk: hunk}}
+	}
+
+	// The "\ No newline" marker belongs to the line before it
+	for i, line := range hunk.Lines {
+		if line.Kind == LineNoNewline && i > 0 {
+			keep[i] = keep[i-1]
+		}
+	}
+
+	var hunks []compactHunk
+	oldLine, newLine := hunk.OldStart, hunk.NewStart
+	var current *Hunk
+	oldCount, newCount := 0, 0
+	flush := func() {
+		if current != nil {
+			current.OldLines, current.NewLines = oldCount, newCount
+			if oldCount == 0 {
+				current.OldStart--
+			}
+			if newCount == 0 {
+				current.NewStart--
+			}
+			current.Header = formatHunkHeader(*current)
+			hunks = append(hunks, compactHunk{Hunk: *current})
+			current = nil
+		}
+	}
+
+	for i, line := range hunk.Lines {
+		if !keep[i] {
+			flush()
+		} else {
+			if current == nil {
+				current = &Hunk{OldStart: oldLine, NewStart: newLine, Section: hunk.Section}
+				oldCount, newCount = 0, 0
+			}
+			current.Lines = append(current.Lines, line)
+			if line.Kind != LineAdded && line.Kind != LineNoNewline {
+				oldCount++
+			}
+			if line.Kind != LineRemoved && line.Kind != LineNoNewline {
+				newCount++
+			}
+		}
+
+		if line.Kind != LineAdded && line.Kind != LineNoNewline {
+			oldLine++
+		}
+		if line.Kind != LineRemoved && line.Kind != LineNoNewline {
+			newLine++
+		}
+	}
+	flush()
+
+	return hunks
+}
+
+// formatHunkHeader renders the "@@ -a,b +c,d @@ section" line of a hunk the way git does,
+// leaving out line counts of one
+func formatHunkHeader(hunk Hunk) string {
+	formatRange := func(start, count int) string {
+		if count == 1 {
+			return fmt.Sprintf("%d", start)
+		}
+		return fmt.Sprintf("%d,%d", start, count)
+	}
+
+	header := fmt.Sprintf("@@ -%s +%s @@", formatRange(hunk.OldStart, hunk.OldLines), formatRange(hunk.NewStart, hunk.NewLines))
+	if hunk.Section != "" {
+		header += " " + hunk.Section
+	}
+	return header
+}
+
+// isWhitespaceOnlyHunk reports whether the removed and added lines of a hunk only differ
+// in whitespace, like a hunk that git diff -w would not show
+func isWhitespaceOnlyHunk(hunk Hunk) bool {
+	var removed, added strings.Builder
+	stripSpace := func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}
+
+	for _, line := range hunk.Lines {
+		switch line.Kind {
+		case LineRemoved:
+			removed.WriteString(strings.Map(stripSpace, line.Text))
+		case LineAdded:
+			added.WriteString(strings.Map(stripSpace, line.Text))
+		}
+	}
+	return removed.String() == added.String()
+}
+
+// dropWhitespaceHunks removes hunks that only change whitespace. Files without any other
+// changes are sent as a stat line.
+func dropWhitespaceHunks(files []*compactFile) {
+	for _, cf := range files {
+		if cf.stat != "" || len(cf.hunks) == 0 {
+			continue
+		}
+
+		var kept []compactHunk
+		for _, hunk := range cf.hunks {
+			if !isWhitespaceOnlyHunk(hunk.Hunk) {
+				kept = append(kept, hunk)
+			}
+		}
+		if len(kept) == 0 {
+			cf.stat = "whitespace only"
+		}
+		cf.hunks = kept
+	}
+}
+
+// collapseLargeChanges sends lockfiles, large data files and large deleted files as stat
+// lines, and replaces hunks that only remove many lines by their line count
+func collapseLargeChanges(files []*compactFile) {
+	for _, cf := range files {
+		if cf.stat != "" || !cf.file.HasTextChanges() {
+			continue
+		}
+
+		added, removed := cf.file.Stats()
+		switch {
+		case isLockfileLike(cf.file.Path):
+			cf.stat = "lockfile"
+			continue
+		case dataFileExtensions[strings.ToLower(filepath.Ext(cf.file.Path))] && added+removed > largeDataFileLines:
+			cf.stat = "data"
+			continue
+		case cf.file.ChangeType == ChangeDeleted && removed > largeDeletionLines:
+			cf.stat = "deleted"
+			continue
+		}
+
+		for i, hunk := range cf.hunks {
+			hunkAdded, hunkRemoved := hunk.Stats()
+			if hunkAdded == 0 && hunkRemoved > largeDeletionLines {
+				cf.hunks[i].collapsed = true
+			}
+		}
+	}
+}
+
+// isLockfileLike reports whether a file is a dependency lockfile or checksum list
+func isLockfileLike(path string) bool {
+	base := filepath.Base(path)
+	return isLockFile(base) || strings.HasSuffix(strings.ToLower(base), ".lock")
+}
+
+// rankedHunk is a hunk considered for leaving out, with its importance
+type rankedHunk struct {
+	file  *compactFile
+	index int
+	order int
+	score float64
+	cost  int
+}
+
+// rankHunks leaves out the least important hunks until the diff fits within tokenLimit.
+// It gives up when that would leave out more than maxRankedOmission of the changed lines.
+func rankHunks(preamble string, files []*compactFile, tokenLimit int) (string, bool) {
+	var candidates []rankedHunk
+	totalLines := 0
+	for _, cf := range files {
+		if cf.stat != "" {
+			continue
+		}
+		weight := fileImportance(cf.file.Path)
+		for i, hunk := range cf.hunks {
+			added, removed := hunk.Stats()
+			totalLines += added + removed
+			candidates = append(candidates, rankedHunk{
+				file:  cf,
+				index: i,
+				order: len(candidates),
+				score: hunkImportance(hunk, weight),
+				cost:  EstimateTokens(renderCompactHunk(hunk)),
+			})
+		}
+	}
+
+	// Least important first; among equals, later hunks go first
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score < candidates[j].score
+		}
+		return candidates[i].order > candidates[j].order
+	})
+
+	tokens := EstimateTokens(renderCompactDiff(preamble, files))
+	omittedLines := 0
+	for _, candidate := range candidates {
+		if tokens <= tokenLimit {
+			break
+		}
+
+		hunk := &candidate.file.hunks[candidate.index]
+		added, removed := hunk.Stats()
+		omittedLines += added + removed
+		if float64(omittedLines) > maxRankedOmission*float64(totalLines) {
+			return "", false
+		}
+		hunk.omitted = true
+		tokens -= candidate.cost
+	}
+
+	// The estimate leaves out the stat lines of omitted hunks, so check the real result
+	result := renderCompactDiff(preamble, files)
+	return result, EstimateTokens(result) <= tokenLimit
+}
+
+// fileImportance weighs a file by its kind: code above tests, tests above docs and data
+func fileImportance(path string) float64 {
+	lower := strings.ToLower(path)
+	base := filepath.Base(lower)
+	ext := filepath.Ext(lower)
+
+	switch {
+	case docFileExtensions[ext] || strings.HasPrefix(lower, "docs/") || strings.Contains(lower, "/docs/"):
+		return 1
+	case dataFileExtensions[ext]:
+		return 1
+	case strings.HasSuffix(base, "_test"+ext) || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(base, "test_") || strings.HasPrefix(lower, "test/") || strings.HasPrefix(lower, "tests/") ||
+		strings.Contains(lower, "/test/") || strings.Contains(lower, "/tests/"):
+		return 2
+	default:
+		return 3
+	}
+}
+
+// hunkImportance scores a hunk by the kind of file, the number of changed lines and
+// whether it changes declarations, which usually carry the intent of a change
+func hunkImportance(hunk compactHunk, weight float64) float64 {
+	if hunk.collapsed {
+		// A collapsed deletion costs almost nothing to keep
+		return weight * 10
+	}
+
+	changed := 0
+	declaration := false
+	for _, line := range hunk.Lines {
+		if line.Kind != LineAdded && line.Kind != LineRemoved {
+			continue
+		}
+		changed++
+		if declarationRegex.MatchString(line.Text) {
+			declaration = true
+		}
+	}
+
+	score := float64(min(changed, 40))
+	if declaration {
+		score += 20
+	}
+	return weight * score
+}
+
+// renderCompactHunk renders a hunk, or its line count when it is collapsed
+func renderCompactHunk(hunk compactHunk) string {
+	if hunk.collapsed {
+		_, removed := hunk.Stats()
+		return fmt.Sprintf("%s\n[%d lines removed]\n", hunk.Header, removed)
+	}
+	return hunk.String()
+}
+
+// renderCompactDiff renders the compacted files, listing files sent as stats and omitted
+// hunks under the same header FilterGeneratedDiff uses for generated files
+func renderCompactDiff(preamble string, files []*compactFile) string {
+	var omitted []string
+	var body strings.Builder
+
+	for _, cf := range files {
+		if cf.stat != "" {
+			omitted = append(omitted, diffStatLine(cf.file, cf.stat))
+			continue
+		}
+		if !cf.file.HasTextChanges() {
+			body.WriteString(cf.file.PromptContent())
+			continue
+		}
+
+		var hunks strings.Builder
+		omittedAdded, omittedRemoved := 0, 0
+		for _, hunk := range cf.hunks {
+			if hunk.omitted {
+				added, removed := hunk.Stats()
+				omittedAdded += added
+				omittedRemoved += removed
+				continue
+			}
+			hunks.WriteString(renderCompactHunk(hunk))
+		}
+
+		if omittedAdded+omittedRemoved > 0 {
+			omitted = append(omitted, fmt.Sprintf(" %s | +%d -%d lower priority", cf.file.Path, omittedAdded, omittedRemoved))
+		}
+		if hunks.Len() > 0 {
+			body.WriteString(cf.file.Header)
+			body.WriteString(hunks.String())
+		}
+	}
+
+	var result strings.Builder
+	result.WriteString(preamble)
+	if len(omitted) > 0 {
+		if preamble != "" && !strings.HasSuffix(preamble, "\n") {
+			result.WriteString("\n")
+		}
+		// Continue the list of a diff that FilterGeneratedDiff already omitted files from
+		if !strings.Contains(preamble, omittedFilesHeader) {
+			result.WriteString(omittedFilesHeader + "\n")
+		}
+		for _, line := range omitted {
+			result.WriteString(line + "\n")
+		}
+	}
+	result.WriteString(body.String())
+	return result.String()
+}
@@ -0,0 +1,164 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReduceHunkContext(t *testing.T) {
+	file := ParseDiff(`diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,12 +1,12 @@ func main() {
+ one
+-two
++TWO
+ three
+ four
+ five
+ six
+ seven
+ eight
+ nine
+-ten
++TEN
+ eleven
+ twelve
+`).Files[0]
+
+	hunks := reduceHunkContext(file.Hunks[0], 1)
+	if len(hunks) != 2 {
+		t.Fatalf("Expected the hunk to be split in two, got %d", len(hunks))
+	}
+
+	expected := []string{
+		"@@ -1,3 +1,3 @@ func main() {\n one\n-two\n+TWO\n three\n",
+		"@@ -9,3 +9,3 @@ func main() {\n nine\n-ten\n+TEN\n eleven\n",
+	}
+	for i, hunk := range hunks {
+		if hunk.String() != expected[i] {
+			t.Errorf("Hunk %d = %q, want %q", i, hunk.String(), expected[i])
+		}
+	}
+}
+
+func TestIsWhitespaceOnlyHunk(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []DiffLine
+		expected bool
+	}{
+		{"reindented", []DiffLine{{Kind: LineRemoved, Text: "\tif x {"}, {Kind: LineAdded, Text: "    if x {"}}, true},
+		{"blank lines", []DiffLine{{Kind: LineContext, Text: "a"}, {Kind: LineAdded, Text: ""}}, true},
+		{"changed code", []DiffLine{{Kind: LineRemoved, Text: "a := 1"}, {Kind: LineAdded, Text: "a := 2"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := isWhitespaceOnlyHunk(Hunk{Lines: tt.lines}); result != tt.expected {
+				t.Errorf("isWhitespaceOnlyHunk() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+// compactTestFile builds the diff of a modified file with the given hunks
+func compactTestFile(path string, hunks ...string) string {
+	return fmt.Sprintf("diff --git a/%s b/%s\nindex 1234567..abcdef0 100644\n--- a/%s\n+++ b/%s\n%s", path, path, path, path, strings.Join(hunks, ""))
+}
+
+// compactTestHunk builds a hunk replacing count lines at start with prefix-numbered lines
+func compactTestHunk(start, count int, removed, added string) string {
+	var hunk strings.Builder
+	fmt.Fprintf(&hunk, "@@ -%d,%d +%d,%d @@\n", start, count, start, count)
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(&hunk, "-%s %d\n", removed, i)
+	}
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(&hunk, "+%s %d\n", added, i)
+	}
+	return hunk.String()
+}
+
+func TestCompactDiff(t *testing.T) {
+	var lockfile strings.Builder
+	lockfile.WriteString("diff --git a/yarn.lock b/yarn.lock\nindex 1234567..abcdef0 100644\n--- a/yarn.lock\n+++ b/yarn.lock\n@@ -1,300 +1,300 @@\n")
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(&lockfile, "-pkg@%d:\n+pkg@%d.1:\n", i, i)
+	}
+
+	var deleted strings.Builder
+	deleted.WriteString("diff --git a/legacy.go b/legacy.go\ndeleted file mode 100644\nindex 1234567..0000000\n--- a/legacy.go\n+++ /dev/null\n@@ -1,200 +0,0 @@\n")
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&deleted, "-legacy line %d\n", i)
+	}
+
+	diff := compactTestFile("main.go", compactTestHunk(10, 2, "\told", "\tnew")) +
+		compactTestFile("style.go", compactTestHunk(1, 40, "\tx := value", "    x := value")) +
+		lockfile.String() + deleted.String()
+
+	t.Run("fits without changes", func(t *testing.T) {
+		result, fits := CompactDiff(diff, EstimateTokens(diff))
+		if !fits || result != diff {
+			t.Errorf("Expected a diff within the limit to be returned as-is")
+		}
+	})
+
+	t.Run("collapses whitespace, lockfiles and deletions", func(t *testing.T) {
+		result, fits := CompactDiff(diff, 200)
+		if !fits {
+			t.Fatalf("Expected the diff to fit, got %d tokens:\n%s", EstimateTokens(result), result)
+		}
+
+		for _, expected := range []string{
+			omittedFilesHeader,
+			" style.go | +40 -40 whitespace only",
+			" yarn.lock | +300 -300 lockfile",
+			" legacy.go | +0 -200 deleted",
+			"+\tnew 1",
+		} {
+			if !strings.Contains(result, expected) {
+				t.Errorf("Expected %q in compacted diff:\n%s", expected, result)
+			}
+		}
+		if strings.Contains(result, "pkg@") || strings.Contains(result, "legacy line") {
+			t.Errorf("Expected collapsed contents to be left out:\n%s", result)
+		}
+	})
+
+	t.Run("too large to compact", func(t *testing.T) {
+		if _, fits := CompactDiff(diff, 10); fits {
+			t.Errorf("Expected the diff not to fit")
+		}
+	})
+}
+
+func TestCompactDiffRanksHunks(t *testing.T) {
+	diff := compactTestFile("README.md", compactTestHunk(1, 10, "old docs", "new docs")) +
+		compactTestFile("server.go", compactTestHunk(1, 10, "\treturn nil", "\treturn err"), compactTestHunk(50, 1, "func serve() {", "func serve(ctx context.Context) {"))
+
+	result, fits := CompactDiff(diff, EstimateTokens(diff)-20)
+	if !fits {
+		t.Fatalf("Expected the diff to fit after ranking:\n%s", result)
+	}
+	if !strings.Contains(result, " README.md | +10 -10 lower priority") {
+		t.Errorf("Expected the documentation hunk to be left out first:\n%s", result)
+	}
+	if !strings.Contains(result, "+func serve(ctx context.Context) {") || !strings.Contains(result, "+\treturn err 0") {
+		t.Errorf("Expected the code hunks to be kept:\n%s", result)
+	}
+}
+
+func TestFormatHunkHeader(t *testing.T) {
+	tests := map[string]Hunk{
+		"@@ -3 +3,2 @@ func a()": {OldStart: 3, OldLines: 1, NewStart: 3, NewLines: 2, Section: "func a()"},
+		"@@ -0,0 +1,4 @@":        {OldStart: 0, OldLines: 0, NewStart: 1, NewLines: 4},
+	}
+
+	for expected, hunk := range tests {
+		if header := formatHunkHeader(hunk); header != expected {
+			t.Errorf("formatHunkHeader() = %q, want %q", header, expected)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	conflictStartRegex  = regexp.MustCompile(`^<{7}(?: (.*))?$`)
+	conflictBaseRegex   = regexp.MustCompile(`^\|{7}(?: (.*))?$`)
+	conflictMiddleRegex = regexp.MustCompile(`^={7}$`)
+	conflictEndRegex    = regexp.MustCompile(`^>{7}(?: (.*))?$`)
+)
+
+// ConflictBlock represents a single region of a file surrounded by merge conflict markers
+type ConflictBlock struct {
+	// StartLine and EndLine are the 0-based indexes of the "<<<<<<<" and ">>>>>>>" marker lines
+	StartLine   int
+	EndLine     int
+	OursLabel   string
+	BaseLabel   string
+	TheirsLabel string
+	Ours        string
+	Base        string
+	Theirs      string
+	// HasBase is set when the block contains a "|||||||" section (diff3 conflict style)
+	HasBase bool
+}
+
+// ParseConflicts extracts all conflict blocks from file content. Incomplete blocks are ignored.
+func ParseConflicts(content string) []ConflictBlock {
+	var blocks []ConflictBlock
+	lines := strings.Split(content, "\n")
+
+	const (
+		outside = iota
+		inOurs
+		inBase
+		inTheirs
+	)
+
+	state := outside
+	var current ConflictBlock
+	var ours, base, theirs []string
+
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+
+		switch state {
+		case outside:
+			if m := conflictStartRegex.FindStringSubmatch(line); m != nil {
+				current = ConflictBlock{StartLine: i, OursLabel: m[1]}
+				ours, base, theirs = nil, nil, nil
+				state = inOurs
+			}
+		case inOurs:
+			if m := conflictBaseRegex.FindStringSubmatch(line); m != nil {
+				current.HasBase = true
+				current.BaseLabel = m[1]
+				state = inBase
+			} else if conflictMiddleRegex.MatchString(line) {
+				state = inTheirs
+			} else if conflictStartRegex.MatchString(line) {
+				// Unterminated block, start over from here
+				current = ConflictBlock{StartLine: i, OursLabel: conflictStartRegex.FindStringSubmatch(line)[1]}
+				ours = nil
+			} else {
+				ours = append(ours, lines[i])
+			}
+		case inBase:
+			if conflictMiddleRegex.MatchString(line) {
+				state = inTheirs
+			} else {
+				base = append(base, lines[i])
+			}
+		case inTheirs:
+			if m := conflictEndRegex.FindStringSubmatch(line); m != nil {
+				current.EndLine = i
+				current.TheirsLabel = m[1]
+				current.Ours = joinConflictLines(ours)
+				current.Base = joinConflictLines(base)
+				current.Theirs = joinConflictLines(theirs)
+				blocks = append(blocks, current)
+				state = outside
+			} else {
+				theirs = append(theirs, lines[i])
+			}
+		}
+	}
+
+	return blocks
+}
+
+// ResolveConflicts replaces conflict blocks with their resolutions. The map is keyed by
+// the index of the block as returned by ParseConflicts; blocks without a resolution are left as is.
+func ResolveConflicts(content string, resolutions map[int]string) string {
+	blocks := ParseConflicts(content)
+	lines := strings.Split(content, "\n")
+
+	var result []string
+	next := 0
+	for i, block := range blocks {
+		resolution, ok := resolutions[i]
+		if !ok {
+			continue
+		}
+		result = append(result, lines[next:block.StartLine]...)
+		if resolution != "" {
+			result = append(result, strings.Split(strings.TrimSuffix(resolution, "\n"), "\n")...)
+		}
+		next = block.EndLine + 1
+	}
+	result = append(result, lines[next:]...)
+
+	return strings.Join(result, "\n")
+}
+
+// joinConflictLines joins the lines of a conflict section, keeping a trailing newline
+func joinConflictLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,79 @@
+package git
+
+import (
+	"testing"
+)
+
+const diff3Conflict = `package main
+
+func greet() string {
+<<<<<<< HEAD
+	return "hello"
+||||||| base
+	return "hi"
+=======
+	return "hi there"
+>>>>>>> feature
+}
+
+func farewell() string {
+<<<<<<< HEAD
+	return "bye"
+=======
+	return "goodbye"
+>>>>>>> feature
+}
+`
+
+func TestParseConflicts(t *testing.T) {
+	blocks := ParseConflicts(diff3Conflict)
+	if len(blocks) != 2 {
+		t.Fatalf("ParseConflicts() returned %d blocks, want 2", len(blocks))
+	}
+
+	first := blocks[0]
+	if !first.HasBase || first.Base != "\treturn \"hi\"\n" {
+		t.Errorf("first block base = %q (HasBase %v)", first.Base, first.HasBase)
+	}
+	if first.Ours != "\treturn \"hello\"\n" || first.Theirs != "\treturn \"hi there\"\n" {
+		t.Errorf("first block ours = %q, theirs = %q", first.Ours, first.Theirs)
+	}
+	if first.OursLabel != "HEAD" || first.TheirsLabel != "feature" {
+		t.Errorf("first block labels = %q, %q", first.OursLabel, first.TheirsLabel)
+	}
+	if first.StartLine != 3 || first.EndLine != 9 {
+		t.Errorf("first block lines = %d-%d, want 3-9", first.StartLine, first.EndLine)
+	}
+
+	second := blocks[1]
+	if second.HasBase {
+		t.Errorf("second block should not have a base section")
+	}
+	if second.Ours != "\treturn \"bye\"\n" || second.Theirs != "\treturn \"goodbye\"\n" {
+		t.Errorf("second block ours = %q, theirs = %q", second.Ours, second.Theirs)
+	}
+}
+
+func TestResolveConflicts(t *testing.T) {
+	resolved := ResolveConflicts(diff3Conflict, map[int]string{1: "\treturn \"see you\"\n"})
+
+	blocks := ParseConflicts(resolved)
+	if len(blocks) != 1 {
+		t.Fatalf("expected one unresolved block, got %d", len(blocks))
+	}
+
+	resolved = ResolveConflicts(resolved, map[int]string{0: "\treturn \"hello there\"\n"})
+	expected := `package main
+
+func greet() string {
+	return "hello there"
+}
+
+func farewell() string {
+	return "see you"
+}
+`
+	if resolved != expected {
+		t.Errorf("ResolveConflicts() = %q, want %q", resolved, expected)
+	}
+}
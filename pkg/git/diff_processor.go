@@ -3,7 +3,6 @@ package git
 import (
 	"fmt"
 	"github.com/recrsn/git-ai/pkg/llm"
-	"regexp"
 	"strings"
 	"sync"
 
@@ -17,56 +16,17 @@ func EstimateTokens(text string) int {
 	return len(text) / 4
 }
 
-// FileDiff represents a single file's diff
-type FileDiff struct {
-	Path    string
-	Content string
-}
+// maxSummaryLevels limits how often summaries are condensed again when they are still too
+// long together
+const maxSummaryLevels = 4
+
+// SummaryProgress is called while a large diff is summarized, with a short status such as
+// "Summarizing 7/23". It may be called from several goroutines at once.
+type SummaryProgress func(status string)
 
 // ParseDiffByFile splits a unified diff into individual file diffs
 func ParseDiffByFile(diff string) []FileDiff {
-	var files []FileDiff
-
-	// Split by file headers (lines starting with "diff --git")
-	parts := regexp.MustCompile(`(?m)^diff --git`).Split(diff, -1)
-
-	// Skip the first empty part
-	for i := 1; i < len(parts); i++ {
-		part := "diff --git" + parts[i]
-
-		// Extract file path from the header
-		lines := strings.Split(part, "\n")
-		var path string
-		for _, line := range lines {
-			if strings.HasPrefix(line, "+++ b/") {
-				path = strings.TrimPrefix(line, "+++ b/")
-				break
-			} else if strings.HasPrefix(line, "+++ ") && !strings.Contains(line, "/dev/null") {
-				path = strings.TrimPrefix(line, "+++ ")
-				break
-			}
-		}
-
-		if path == "" {
-			// Try to extract from diff header
-			for _, line := range lines {
-				if strings.HasPrefix(line, "diff --git a/") {
-					parts := strings.Fields(line)
-					if len(parts) >= 4 {
-						path = strings.TrimPrefix(parts[3], "b/")
-					}
-					break
-				}
-			}
-		}
-
-		files = append(files, FileDiff{
-			Path:    path,
-			Content: part,
-		})
-	}
-
-	return files
+	return ParseDiff(diff).Files
 }
 
 // createFileBatches groups files into batches where each batch doesn't exceed token limit
@@ -76,7 +36,7 @@ func createFileBatches(fileDiffs []FileDiff, tokenLimit int) [][]FileDiff {
 	currentBatchTokens := 0
 
 	for _, fileDiff := range fileDiffs {
-		fileTokens := EstimateTokens(fileDiff.Content)
+		fileTokens := EstimateTokens(fileDiff.String())
 
 		// If single file exceeds limit, put it in its own batch
 		if fileTokens > tokenLimit {
@@ -108,8 +68,29 @@ func createFileBatches(fileDiffs []FileDiff, tokenLimit int) [][]FileDiff {
 	return batches
 }
 
-// summarizeBatch summarizes a batch of file diffs together
-func summarizeBatch(cfg config.Config, fileBatch []FileDiff) (string, error) {
+// summarizeBatch summarizes a batch of file diffs together. Files with a cached summary
+// are left out of the request, and the summaries of the others are cached.
+func summarizeBatch(cfg config.Config, cache *SummaryCache, fileBatch []FileDiff) (string, error) {
+	var cached []string
+	var uncached []FileDiff
+	for _, fileDiff := range fileBatch {
+		if summary, ok := cache.Get(summaryCacheKey(cfg, fileDiff)); ok {
+			if summary != "" {
+				cached = append(cached, summary)
+			}
+			continue
+		}
+		uncached = append(uncached, fileDiff)
+	}
+
+	if len(uncached) == 0 {
+		logger.Debug("Using cached summaries for %d files", len(fileBatch))
+		if len(cached) == 0 {
+			return "MINOR CHANGES ONLY", nil
+		}
+		return strings.Join(cached, "\n"), nil
+	}
+
 	if cfg.APIKey == "" {
 		return "", config.ErrLLMNotConfigured
 	}
@@ -121,11 +102,11 @@ func summarizeBatch(cfg config.Config, fileBatch []FileDiff) (string, error) {
 
 	// Combine all files in batch into single diff
 	var combinedContent strings.Builder
-	for i, fileDiff := range fileBatch {
+	for i, fileDiff := range uncached {
 		if i > 0 {
 			combinedContent.WriteString("\n\n")
 		}
-		combinedContent.WriteString(fileDiff.Content)
+		combinedContent.WriteString(fileDiff.PromptContent())
 	}
 
 	systemPrompt := llm.GetDiffSummarySystemPrompt()
@@ -148,85 +129,401 @@ func summarizeBatch(cfg config.Config, fileBatch []FileDiff) (string, error) {
 	}
 
 	summary := strings.TrimSpace(response)
-	return summary, nil
+	cacheBatchSummary(cfg, cache, uncached, summary)
+
+	if len(cached) == 0 {
+		return summary, nil
+	}
+	if summary == "MINOR CHANGES ONLY" {
+		return strings.Join(cached, "\n"), nil
+	}
+	return strings.Join(append(cached, summary), "\n"), nil
+}
+
+// cacheBatchSummary splits the summary of a batch into the "path: description" lines of
+// each file and caches them per file. Files without lines had only minor changes. Nothing
+// is cached when a line can't be attributed to a single file of the batch.
+func cacheBatchSummary(cfg config.Config, cache *SummaryCache, fileBatch []FileDiff, summary string) {
+	if cache == nil {
+		return
+	}
+
+	byPath := make(map[string][]string)
+	for _, fileDiff := range fileBatch {
+		if _, seen := byPath[fileDiff.Path]; seen {
+			// Parts of the same file can't be told apart in the summary
+			return
+		}
+		byPath[fileDiff.Path] = nil
+	}
+
+	if summary != "MINOR CHANGES ONLY" {
+		for _, line := range summaryLines(summary) {
+			path, _, ok := strings.Cut(line, ": ")
+			path = strings.Trim(path, "`")
+			if _, known := byPath[path]; !ok || !known {
+				return
+			}
+			byPath[path] = append(byPath[path], line)
+		}
+	}
+
+	for _, fileDiff := range fileBatch {
+		cache.Put(summaryCacheKey(cfg, fileDiff), strings.Join(byPath[fileDiff.Path], "\n"))
+	}
 }
 
-// ProcessDiffWithSummarization handles large diffs by summarizing files in parallel
+// ProcessDiffWithSummarization handles large diffs by compacting them and, if that is not
+// enough, summarizing files in parallel. Files too large for one request are summarized in
+// parts, and summaries that are still too long together are condensed again until they fit.
+// The summaries name the files they describe, one "path: description" line per file.
+// Requests are sent with the concurrency configured for the provider, and progress, if not
+// nil, is told how many of them are done.
 // Returns the processed diff, a boolean indicating if summarization occurred, and any error
-func ProcessDiffWithSummarization(cfg config.Config, diff string, tokenLimit int) (string, bool, error) {
+func ProcessDiffWithSummarization(cfg config.Config, diff string, tokenLimit int, progress SummaryProgress) (string, bool, error) {
 	// If diff is small enough, return as-is
 	if EstimateTokens(diff) <= tokenLimit {
 		return diff, false, nil
 	}
 
+	// Try to fit the diff by compacting it before spending LLM calls on summaries
+	compacted, fits := CompactDiff(diff, tokenLimit)
+	if fits {
+		logger.Debug("Compacted diff to %d tokens (from %d tokens)", EstimateTokens(compacted), EstimateTokens(diff))
+		return compacted, false, nil
+	}
+	diff = compacted
+
 	logger.Debug("Diff exceeds token limit (%d tokens estimated), summarizing by file", EstimateTokens(diff))
 
 	// Parse diff by file
-	fileDiffs := ParseDiffByFile(diff)
+	parsed := ParseDiff(diff)
+	fileDiffs := parsed.Files
 	if len(fileDiffs) == 0 {
 		return diff, false, nil // Return original if parsing fails
 	}
 
-	// Create batches of files to process together
-	batches := createFileBatches(fileDiffs, tokenLimit)
+	paths := make(map[string]bool)
+	for _, fileDiff := range fileDiffs {
+		paths[fileDiff.Path] = true
+		if fileDiff.OldPath != "" {
+			paths[fileDiff.OldPath] = true
+		}
+	}
+
+	// Create batches of files to process together, splitting files that don't fit in one
+	batches := createFileBatches(splitLargeFileDiffs(fileDiffs, tokenLimit), tokenLimit)
+
+	// Summaries are cached per file, so that summarizing the same changes again is cheap
+	cache := OpenSummaryCache(cfg)
+	defer cache.Prune()
 
 	// Process batches in parallel with limited concurrency
-	semaphore := make(chan struct{}, 4) // Limit to 4 concurrent requests
+	concurrency := summaryConcurrency(cfg)
+	logger.Debug("Summarizing %d batches, %d at a time", len(batches), concurrency)
+	batchEntries := make([][]string, len(batches))
+	runParallel(concurrency, len(batches), reportProgress(progress, "Summarizing", len(batches)), func(index int) {
+		fileBatch := batches[index]
+		summary, err := summarizeBatch(cfg, cache, fileBatch)
+		if err != nil {
+			logger.Warn("Failed to summarize batch %d: %v", index, err)
+			// Use truncated version as fallback
+			for _, fd := range fileBatch {
+				content := fd.String()
+				if len(content) > 500 {
+					content = content[:500] + "... (truncated)"
+				}
+				batchEntries[index] = append(batchEntries[index], fmt.Sprintf("File: %s\n%s", fd.Path, content))
+			}
+		} else if summary != "MINOR CHANGES ONLY" {
+			// Formatting-only changes are skipped
+			batchEntries[index] = summaryLines(summary)
+		}
+	})
+
+	var entries []string
+	for _, batch := range batchEntries {
+		entries = append(entries, batch...)
+	}
+	entries = attributeSummaries(entries, paths)
+
+	// Keep anything before the first file, like the stat lines of omitted generated files
+	preamble := ""
+	if strings.TrimSpace(parsed.Preamble) != "" {
+		preamble = strings.TrimSpace(parsed.Preamble) + "\n\n"
+	}
+
+	// If all changes were formatting-only, return a simple message
+	if len(entries) == 0 {
+		return preamble + "Minor formatting and refactoring changes with no functional impact.", true, nil
+	}
+
+	entries = reduceSummaries(cfg, entries, paths, tokenLimit, progress)
+
+	// Combine summaries
+	result := preamble + "# Summarized Changes\n\n" + strings.Join(entries, "\n")
+
+	logger.Debug("Summarized diff: %d tokens (from %d tokens)", EstimateTokens(result), EstimateTokens(diff))
+
+	return result, true, nil
+}
+
+// summaryConcurrency returns the number of summary requests sent at the same time, from
+// the summary_concurrency setting of the provider or its default
+func summaryConcurrency(cfg config.Config) int {
+	if concurrency := cfg.SummaryConcurrency[cfg.Provider]; concurrency > 0 {
+		return concurrency
+	}
+	return llm.DefaultConcurrency(cfg.Provider)
+}
+
+// reportProgress returns a callback that reports the number of finished requests out of
+// total to progress, or nil when there is nothing to report to
+func reportProgress(progress SummaryProgress, action string, total int) func(done int) {
+	if progress == nil {
+		return nil
+	}
+
+	progress(fmt.Sprintf("%s %d/%d", action, 0, total))
+	return func(done int) {
+		progress(fmt.Sprintf("%s %d/%d", action, done, total))
+	}
+}
+
+// runParallel calls fn for 0..n-1, at most concurrency at a time, and waits for all calls
+// to finish. onDone, if not nil, is called with the number of finished calls.
+func runParallel(concurrency, n int, onDone func(done int), fn func(index int)) {
+	semaphore := make(chan struct{}, max(concurrency, 1))
 	var wg sync.WaitGroup
-	summaries := make([]string, len(batches))
-	errors := make([]error, len(batches))
+	var mu sync.Mutex
+	done := 0
 
-	for i, batch := range batches {
+	for i := 0; i < n; i++ {
 		wg.Add(1)
-		go func(index int, fileBatch []FileDiff) {
+		go func(index int) {
 			defer wg.Done()
 
 			// Acquire semaphore
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }() // Release semaphore
 
-			summary, err := summarizeBatch(cfg, fileBatch)
-			if err != nil {
-				logger.Warn("Failed to summarize batch %d: %v", index, err)
-				errors[index] = err
-				// Use truncated version as fallback
-				var fallbackParts []string
-				for _, fd := range fileBatch {
-					content := fd.Content
-					if len(content) > 500 {
-						content = content[:500] + "... (truncated)"
-					}
-					fallbackParts = append(fallbackParts, fmt.Sprintf("File: %s\n%s", fd.Path, content))
-				}
-				summaries[index] = strings.Join(fallbackParts, "\n\n")
-			} else if summary == "MINOR CHANGES ONLY" {
-				// Skip formatting-only changes
-				summaries[index] = ""
-			} else {
-				summaries[index] = summary
+			fn(index)
+
+			if onDone != nil {
+				mu.Lock()
+				done++
+				onDone(done)
+				mu.Unlock()
 			}
-		}(i, batch)
+		}(i)
 	}
 
 	wg.Wait()
+}
 
-	// Filter out empty summaries (formatting-only changes)
-	var filteredSummaries []string
-	for _, summary := range summaries {
-		if summary != "" {
-			filteredSummaries = append(filteredSummaries, summary)
+// splitLargeFileDiffs splits files that exceed tokenLimit on their own into several file
+// diffs with the same header and a part of the hunks each, so that every part fits in one
+// summary request
+func splitLargeFileDiffs(fileDiffs []FileDiff, tokenLimit int) []FileDiff {
+	var result []FileDiff
+	for _, fileDiff := range fileDiffs {
+		if !fileDiff.HasTextChanges() || EstimateTokens(fileDiff.String()) <= tokenLimit {
+			result = append(result, fileDiff)
+			continue
 		}
+
+		headerTokens := EstimateTokens(fileDiff.Header)
+		var current []Hunk
+		currentTokens := headerTokens
+		flush := func() {
+			if len(current) > 0 {
+				part := fileDiff
+				part.Hunks = current
+				result = append(result, part)
+				current = nil
+				currentTokens = headerTokens
+			}
+		}
+
+		for _, hunk := range fileDiff.Hunks {
+			for _, piece := range splitHunk(hunk, max(tokenLimit-headerTokens, 1)) {
+				pieceTokens := EstimateTokens(piece.String())
+				if currentTokens+pieceTokens > tokenLimit {
+					flush()
+				}
+				current = append(current, piece)
+				currentTokens += pieceTokens
+			}
+		}
+		flush()
 	}
+	return result
+}
 
-	// If all changes were formatting-only, return a simple message
-	if len(filteredSummaries) == 0 {
-		return "Minor formatting and refactoring changes with no functional impact.", true, nil
+// splitHunk splits a hunk that exceeds tokenLimit into pieces of consecutive lines. The
+// pieces keep the original header, so they are only meant to be summarized, not applied.
+func splitHunk(hunk Hunk, tokenLimit int) []Hunk {
+	if EstimateTokens(hunk.String()) <= tokenLimit {
+		return []Hunk{hunk}
 	}
 
-	// Combine summaries
-	result := "# Summarized Changes\n\n" + strings.Join(filteredSummaries, "\n\n")
+	var pieces []Hunk
+	piece := hunk
+	piece.Lines = nil
+	// Sizes are in characters, like EstimateTokens counts them
+	size := len(hunk.Header) + 1
+	for _, line := range hunk.Lines {
+		lineSize := len(line.Text) + 2
+		if len(piece.Lines) > 0 && (size+lineSize)/4 > tokenLimit {
+			pieces = append(pieces, piece)
+			piece.Lines = nil
+			size = len(hunk.Header) + 1
+		}
+		piece.Lines = append(piece.Lines, line)
+		size += lineSize
+	}
+	if len(piece.Lines) > 0 {
+		pieces = append(pieces, piece)
+	}
+	return pieces
+}
 
-	logger.Debug("Summarized diff: %d tokens (from %d tokens)", EstimateTokens(result), EstimateTokens(diff))
+// summaryLines splits an LLM summary into its lines, dropping blank lines, code fences and
+// list markers
+func summaryLines(summary string) []string {
+	var lines []string
+	for _, line := range strings.Split(summary, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "```") {
+			continue
+		}
+		lines = append(lines, strings.TrimPrefix(line, "- "))
+	}
+	return lines
+}
 
-	return result, true, nil
+// attributeSummaries joins "path: description" entries about the same files, e.g. from the
+// parts of a file that was summarized in several requests. Other entries are kept as they are.
+func attributeSummaries(entries []string, paths map[string]bool) []string {
+	var result []string
+	index := make(map[string]int)
+
+	for _, entry := range entries {
+		key, description, ok := strings.Cut(entry, ": ")
+		if !ok || strings.Contains(entry, "\n") || !isPathList(key, paths) {
+			result = append(result, entry)
+			continue
+		}
+
+		if i, seen := index[key]; seen {
+			result[i] += "; " + description
+			continue
+		}
+		index[key] = len(result)
+		result = append(result, entry)
+	}
+	return result
+}
+
+// isPathList reports whether s is a comma-separated list of paths from the diff
+func isPathList(s string, paths map[string]bool) bool {
+	for _, path := range strings.Split(s, ", ") {
+		if !paths[strings.Trim(path, "`")] {
+			return false
+		}
+	}
+	return true
+}
+
+// reduceSummaries condenses summary entries with the LLM, in groups that fit within
+// tokenLimit, until all of them together fit. It gives up after maxSummaryLevels rounds
+// or when a round does not make the summaries shorter.
+func reduceSummaries(cfg config.Config, entries []string, paths map[string]bool, tokenLimit int, progress SummaryProgress) []string {
+	for level := 1; EstimateTokens(strings.Join(entries, "\n")) > tokenLimit; level++ {
+		tokens := EstimateTokens(strings.Join(entries, "\n"))
+		if level > maxSummaryLevels {
+			logger.Warn("Summaries are still about %d tokens after %d rounds of condensing", tokens, maxSummaryLevels)
+			break
+		}
+
+		logger.Debug("Summaries exceed token limit (%d tokens estimated), condensing them (round %d)", tokens, level)
+
+		groups := groupSummaryEntries(entries, tokenLimit)
+		merged := make([][]string, len(groups))
+		runParallel(summaryConcurrency(cfg), len(groups), reportProgress(progress, "Condensing summaries", len(groups)), func(index int) {
+			lines, err := mergeSummaries(cfg, groups[index])
+			if err != nil || len(lines) == 0 {
+				logger.Warn("Failed to condense summaries %d: %v", index, err)
+				merged[index] = groups[index]
+				return
+			}
+			merged[index] = lines
+		})
+
+		var next []string
+		for _, group := range merged {
+			next = append(next, group...)
+		}
+		next = attributeSummaries(next, paths)
+
+		if EstimateTokens(strings.Join(next, "\n")) >= tokens {
+			logger.Warn("Condensing did not shorten the summaries, keeping them at about %d tokens", tokens)
+			break
+		}
+		entries = next
+	}
+	return entries
+}
+
+// groupSummaryEntries groups entries so that each group doesn't exceed tokenLimit
+func groupSummaryEntries(entries []string, tokenLimit int) [][]string {
+	var groups [][]string
+	var current []string
+	currentTokens := 0
+
+	for _, entry := range entries {
+		entryTokens := EstimateTokens(entry + "\n")
+		if currentTokens+entryTokens > tokenLimit && len(current) > 0 {
+			groups = append(groups, current)
+			current = nil
+			currentTokens = 0
+		}
+		current = append(current, entry)
+		currentTokens += entryTokens
+	}
+
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+	return groups
+}
+
+// mergeSummaries asks the LLM to condense a group of summary entries
+func mergeSummaries(cfg config.Config, entries []string) ([]string, error) {
+	if cfg.APIKey == "" {
+		return nil, config.ErrLLMNotConfigured
+	}
+
+	client, err := llm.NewClientWithProvider(cfg.Provider, cfg.Endpoint, cfg.APIKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	messages := []llm.Message{
+		{
+			Role:    "system",
+			Content: llm.GetDiffSummaryMergeSystemPrompt(),
+		},
+		{
+			Role:    "user",
+			Content: fmt.Sprintf("Condense these summaries:\n\n```\n%s\n```", strings.Join(entries, "\n")),
+		},
+	}
+
+	response, err := client.ChatCompletion(cfg.Model, messages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to condense summaries: %w", err)
+	}
+
+	return summaryLines(response), nil
 }
@@ -0,0 +1,187 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/llm"
+)
+
+// largeTestFileDiff builds the diff of big.go with three hunks of 40 changed lines
+func largeTestFileDiff() string {
+	var large strings.Builder
+	large.WriteString("diff --git a/big.go b/big.go\nindex 1234567..abcdef0 100644\n--- a/big.go\n+++ b/big.go\n")
+	for h := 0; h < 3; h++ {
+		fmt.Fprintf(&large, "@@ -%d,40 +%d,40 @@\n", h*100+1, h*100+1)
+		for i := 0; i < 40; i++ {
+			fmt.Fprintf(&large, "-\tline %d of hunk %d\n+\tLINE %d of hunk %d\n", i, h, i, h)
+		}
+	}
+	return large.String()
+}
+
+func TestSplitLargeFileDiffs(t *testing.T) {
+	small := "diff --git a/small.go b/small.go\nindex 1234567..abcdef0 100644\n--- a/small.go\n+++ b/small.go\n@@ -1 +1 @@\n-a\n+b\n"
+
+	files := ParseDiff(largeTestFileDiff() + small).Files
+	parts := splitLargeFileDiffs(files, 300)
+	if len(parts) < 4 {
+		t.Fatalf("Expected big.go to be split into several parts, got %d files", len(parts))
+	}
+
+	added, removed := 0, 0
+	for _, part := range parts[:len(parts)-1] {
+		if part.Path != "big.go" || part.Header != files[0].Header {
+			t.Errorf("Expected every part to keep the header of big.go, got %q", part.Path)
+		}
+		if tokens := EstimateTokens(part.String()); tokens > 300 {
+			t.Errorf("Expected every part to fit, got %d tokens", tokens)
+		}
+		partAdded, partRemoved := part.Stats()
+		added += partAdded
+		removed += partRemoved
+	}
+	if added != 120 || removed != 120 {
+		t.Errorf("Expected the parts to hold all changes, got +%d -%d", added, removed)
+	}
+	if parts[len(parts)-1].Path != "small.go" {
+		t.Errorf("Expected small.go to be kept whole")
+	}
+}
+
+func TestSummaryLines(t *testing.T) {
+	summary := "```\nsrc/a.go: Added retries\n\n- src/b.go: Fixed timeout\n```\n"
+	expected := []string{"src/a.go: Added retries", "src/b.go: Fixed timeout"}
+	if lines := summaryLines(summary); !reflect.DeepEqual(lines, expected) {
+		t.Errorf("summaryLines() = %q, want %q", lines, expected)
+	}
+}
+
+func TestAttributeSummaries(t *testing.T) {
+	paths := map[string]bool{"big.go": true, "a.go": true, "b.go": true}
+	entries := []string{
+		"big.go: Added request parsing",
+		"a.go, b.go: Renamed the config loader",
+		"big.go: Added response encoding",
+		"Note: something unattributed",
+		"File: c.go\ndiff --git a/c.go b/c.go",
+	}
+
+	expected := []string{
+		"big.go: Added request parsing; Added response encoding",
+		"a.go, b.go: Renamed the config loader",
+		"Note: something unattributed",
+		"File: c.go\ndiff --git a/c.go b/c.go",
+	}
+	if result := attributeSummaries(entries, paths); !reflect.DeepEqual(result, expected) {
+		t.Errorf("attributeSummaries() = %q, want %q", result, expected)
+	}
+}
+
+func TestGroupSummaryEntries(t *testing.T) {
+	entries := []string{strings.Repeat("a", 39), strings.Repeat("b", 39), strings.Repeat("c", 39)}
+	groups := groupSummaryEntries(entries, 20)
+	if len(groups) != 2 || len(groups[0]) != 2 || len(groups[1]) != 1 {
+		t.Errorf("Expected groups of 2 and 1 entries, got %q", groups)
+	}
+}
+
+func TestProcessDiffWithSummarizationCondensesSummaries(t *testing.T) {
+	var summaryCalls, condenseCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.OpenAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+
+		// Summaries of the parts are too long together, so they have to be condensed
+		content := "big.go: " + strings.Repeat("changed lines ", 40)
+		if strings.HasPrefix(req.Messages[1].Content, "Condense") {
+			condenseCalls.Add(1)
+			content = "big.go: condensed"
+		} else {
+			summaryCalls.Add(1)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(llm.OpenAIResponse{
+			Choices: []llm.OpenAIChoice{{Message: llm.OpenAIMessage{Role: "assistant", Content: content}}},
+		})
+	}))
+	defer server.Close()
+
+	cfg := config.Config{Provider: "openai", APIKey: "test", Model: "test", Endpoint: server.URL}
+	result, summarized, err := ProcessDiffWithSummarization(cfg, largeTestFileDiff(), 300, nil)
+	if err != nil || !summarized {
+		t.Fatalf("Expected the diff to be summarized, got %v, %v", summarized, err)
+	}
+
+	if summaryCalls.Load() < 2 {
+		t.Errorf("Expected big.go to be summarized in parts, got %d requests", summaryCalls.Load())
+	}
+	if condenseCalls.Load() == 0 {
+		t.Errorf("Expected the summaries to be condensed")
+	}
+	if result != "# Summarized Changes\n\nbig.go: condensed" {
+		t.Errorf("Unexpected result %q", result)
+	}
+}
+
+func TestRunParallel(t *testing.T) {
+	var running, peak atomic.Int32
+	var reported []int
+	results := make([]int, 10)
+
+	runParallel(2, len(results), func(done int) {
+		reported = append(reported, done)
+	}, func(index int) {
+		current := running.Add(1)
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		results[index] = index * 2
+		running.Add(-1)
+	})
+
+	if peak.Load() > 2 {
+		t.Errorf("Expected at most 2 calls at a time, got %d", peak.Load())
+	}
+	for i, result := range results {
+		if result != i*2 {
+			t.Errorf("Expected call %d to run, got %d", i, result)
+		}
+	}
+	if !reflect.DeepEqual(reported, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}) {
+		t.Errorf("Unexpected progress %v", reported)
+	}
+}
+
+func TestSummaryConcurrency(t *testing.T) {
+	tests := []struct {
+		cfg      config.Config
+		expected int
+	}{
+		{config.Config{Provider: "openai"}, 4},
+		{config.Config{Provider: "ollama"}, 1},
+		{config.Config{Provider: "ollama", SummaryConcurrency: map[string]int{"ollama": 2}}, 2},
+		{config.Config{Provider: "openai", SummaryConcurrency: map[string]int{"ollama": 2}}, 4},
+	}
+
+	for _, tt := range tests {
+		if concurrency := summaryConcurrency(tt.cfg); concurrency != tt.expected {
+			t.Errorf("summaryConcurrency(%+v) = %d, want %d", tt.cfg, concurrency, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+)
+
+// LineKind is the type of a line in a hunk, given by its first character
+type LineKind byte
+
+const (
+	LineContext   LineKind = ' '
+	LineAdded     LineKind = '+'
+	LineRemoved   LineKind = '-'
+	LineNoNewline LineKind = '\\'
+)
+
+// DiffLine is a single line of a hunk. Line numbers are 1-based and zero when the line
+// does not exist on that side, e.g. OldNumber for added lines.
+type DiffLine struct {
+	Kind      LineKind
+	Text      string
+	OldNumber int
+	NewNumber int
+}
+
+// String returns the line as it appears in the diff, without the trailing newline
+func (l DiffLine) String() string {
+	return string(l.Kind) + l.Text
+}
+
+// Hunk is a "@@ -a,b +c,d @@" section of a file diff
+type Hunk struct {
+	// Header is the full "@@" line
+	Header   string
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	// Section is the enclosing function or section git reports after the "@@" markers
+	Section string
+	Lines   []DiffLine
+}
+
+// String returns the hunk as it appears in the diff
+func (h Hunk) String() string {
+	var result strings.Builder
+	result.WriteString(h.Header + "\n")
+	for _, line := range h.Lines {
+		result.WriteString(line.String() + "\n")
+	}
+	return result.String()
+}
+
+// Stats returns the number of added and removed lines in the hunk
+func (h Hunk) Stats() (added, removed int) {
+	for _, line := range h.Lines {
+		switch line.Kind {
+		case LineAdded:
+			added++
+		case LineRemoved:
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// Diff is a parsed unified diff as produced by git diff
+type Diff struct {
+	// Preamble is any text before the first file, e.g. the commit header of git show
+	Preamble string
+	Files    []FileDiff
+}
+
+// String returns the diff as text. For diffs ending in a newline, like all git output,
+// this is exactly the text that was parsed.
+func (d Diff) String() string {
+	var result strings.Builder
+	result.WriteString(d.Preamble)
+	for _, file := range d.Files {
+		result.WriteString(file.String())
+	}
+	return result.String()
+}
+
+// ParseDiff parses a unified diff into files, hunks and lines
+func ParseDiff(diff string) Diff {
+	var result Diff
+	var preamble, header strings.Builder
+	var file *FileDiff
+	var hunk *Hunk
+	oldLine, newLine := 0, 0
+
+	flushHunk := func() {
+		if hunk != nil {
+			file.Hunks = append(file.Hunks, *hunk)
+			hunk = nil
+		}
+	}
+	flushFile := func() {
+		if file == nil {
+			return
+		}
+		flushHunk()
+		file.Header = header.String()
+		parseFileDiffHeader(file)
+		result.Files = append(result.Files, *file)
+		file = nil
+	}
+
+	for _, raw := range strings.SplitAfter(diff, "\n") {
+		if raw == "" {
+			continue
+		}
+		line := strings.TrimSuffix(raw, "\n")
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flushFile()
+			file = &FileDiff{}
+			header.Reset()
+			header.WriteString(line + "\n")
+		case file == nil:
+			preamble.WriteString(raw)
+		case strings.HasPrefix(line, "@@ "):
+			// Lines inside hunks always start with a marker, so "@@" always starts a new hunk
+			flushHunk()
+			hunk = parseHunkHeader(line)
+			oldLine, newLine = hunk.OldStart, hunk.NewStart
+		case hunk != nil:
+			diffLine := DiffLine{Kind: LineContext}
+			if line != "" {
+				diffLine.Kind = LineKind(line[0])
+				diffLine.Text = line[1:]
+			}
+			switch diffLine.Kind {
+			case LineAdded:
+				diffLine.NewNumber = newLine
+				newLine++
+			case LineRemoved:
+				diffLine.OldNumber = oldLine
+				oldLine++
+			case LineNoNewline:
+			default:
+				diffLine.OldNumber, diffLine.NewNumber = oldLine, newLine
+				oldLine++
+				newLine++
+			}
+			hunk.Lines = append(hunk.Lines, diffLine)
+		default:
+			header.WriteString(line + "\n")
+		}
+	}
+	flushFile()
+
+	result.Preamble = preamble.String()
+	return result
+}
+
+// parseHunkHeader parses a "@@ -a,b +c,d @@ section" line
+func parseHunkHeader(line string) *Hunk {
+	hunk := &Hunk{Header: line, OldLines: 1, NewLines: 1}
+	m := hunkHeaderRegex.FindStringSubmatch(line)
+	if m == nil {
+		return hunk
+	}
+
+	hunk.OldStart, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		hunk.OldLines, _ = strconv.Atoi(m[2])
+	}
+	hunk.NewStart, _ = strconv.Atoi(m[3])
+	if m[4] != "" {
+		hunk.NewLines, _ = strconv.Atoi(m[4])
+	}
+	hunk.Section = strings.TrimSpace(line[len(m[0]):])
+	return hunk
+}
+
+// parseDiffGitPaths extracts the old and new path from a "diff --git a/x b/y" line. Paths
+// with special characters are C-quoted by git; unquoted paths with spaces are ambiguous, so
+// the line is split in the middle when both sides are equal.
+func parseDiffGitPaths(line string) (oldPath, newPath string) {
+	rest := strings.TrimPrefix(line, "diff --git ")
+
+	if strings.HasPrefix(rest, `"`) {
+		oldToken, remaining := cutQuotedPath(rest)
+		return trimPathPrefix(unquoteGitPath(oldToken)), trimPathPrefix(unquoteGitPath(strings.TrimPrefix(remaining, " ")))
+	}
+	if idx := strings.Index(rest, ` "b/`); idx != -1 {
+		return trimPathPrefix(rest[:idx]), trimPathPrefix(unquoteGitPath(rest[idx+1:]))
+	}
+
+	// Same path on both sides, the common case
+	if len(rest)%2 == 1 {
+		half := len(rest) / 2
+		oldSide, newSide := rest[:half], rest[half+1:]
+		if rest[half] == ' ' && strings.HasPrefix(oldSide, "a/") && strings.HasPrefix(newSide, "b/") && oldSide[2:] == newSide[2:] {
+			return oldSide[2:], newSide[2:]
+		}
+	}
+
+	if idx := strings.Index(rest, " b/"); idx != -1 {
+		return trimPathPrefix(rest[:idx]), trimPathPrefix(rest[idx+1:])
+	}
+	return "", ""
+}
+
+// cutQuotedPath splits a leading C-quoted path from the rest of the string
+func cutQuotedPath(s string) (quoted, rest string) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return s[:i+1], s[i+1:]
+		}
+	}
+	return s, ""
+}
+
+// headerPath extracts the path from the value of a "---"/"+++" line, or "rename from"
+// and similar lines, removing the quoting, trailing tab and "a/"/"b/" prefix
+func headerPath(value string, prefixed bool) string {
+	// Paths with spaces end in a tab
+	value = unquoteGitPath(strings.TrimSuffix(value, "\t"))
+	if value == "/dev/null" {
+		return ""
+	}
+	if prefixed {
+		return trimPathPrefix(value)
+	}
+	return value
+}
+
+// trimPathPrefix removes the "a/" or "b/" prefix git adds to paths in diffs
+func trimPathPrefix(path string) string {
+	if strings.HasPrefix(path, "a/") || strings.HasPrefix(path, "b/") {
+		return path[2:]
+	}
+	return path
+}
+
+// unquoteGitPath decodes a path that git quoted because it contains special characters,
+// e.g. "a/t\303\244st.txt". Unquoted paths are returned as they are.
+func unquoteGitPath(path string) string {
+	if len(path) < 2 || path[0] != '"' || path[len(path)-1] != '"' {
+		return path
+	}
+
+	path = path[1 : len(path)-1]
+	var result []byte
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c != '\\' || i+1 >= len(path) {
+			result = append(result, c)
+			continue
+		}
+
+		i++
+		switch path[i] {
+		case 'a':
+			result = append(result, '\a')
+		case 'b':
+			result = append(result, '\b')
+		case 'f':
+			result = append(result, '\f')
+		case 'n':
+			result = append(result, '\n')
+		case 'r':
+			result = append(result, '\r')
+		case 't':
+			result = append(result, '\t')
+		case 'v':
+			result = append(result, '\v')
+		case '0', '1', '2', '3':
+			// Octal escape of a single byte, e.g. \303
+			if i+2 < len(path) {
+				if value, err := strconv.ParseUint(path[i:i+3], 8, 8); err == nil {
+					result = append(result, byte(value))
+					i += 2
+					continue
+				}
+			}
+			result = append(result, path[i])
+		default:
+			// \" and \\
+			result = append(result, path[i])
+		}
+	}
+	return string(result)
+}
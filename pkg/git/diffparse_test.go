@@ -0,0 +1,204 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDiffLines(t *testing.T) {
+	diff := `commit 1234567
+Author: Someone
+
+diff --git a/main.go b/main.go
+index 1234567..abcdef0 100644
+--- a/main.go
++++ b/main.go
+@@ -10,4 +10,5 @@ func main() {
+ 	setup()
+-	run()
++	run(ctx)
++	wait()
+ }
+\ No newline at end of file
+`
+
+	parsed := ParseDiff(diff)
+	if parsed.Preamble != "commit 1234567\nAuthor: Someone\n\n" {
+		t.Errorf("Unexpected preamble %q", parsed.Preamble)
+	}
+	if len(parsed.Files) != 1 || len(parsed.Files[0].Hunks) != 1 {
+		t.Fatalf("Expected one file with one hunk, got %+v", parsed.Files)
+	}
+
+	hunk := parsed.Files[0].Hunks[0]
+	if hunk.OldStart != 10 || hunk.OldLines != 4 || hunk.NewStart != 10 || hunk.NewLines != 5 {
+		t.Errorf("Unexpected hunk range %+v", hunk)
+	}
+	if hunk.Section != "func main() {" {
+		t.Errorf("Section = %q, want %q", hunk.Section, "func main() {")
+	}
+
+	expected := []DiffLine{
+		{Kind: LineContext, Text: "\tsetup()", OldNumber: 10, NewNumber: 10},
+		{Kind: LineRemoved, Text: "\trun()", OldNumber: 11},
+		{Kind: LineAdded, Text: "\trun(ctx)", NewNumber: 11},
+		{Kind: LineAdded, Text: "\twait()", NewNumber: 12},
+		{Kind: LineContext, Text: "}", OldNumber: 12, NewNumber: 13},
+		{Kind: LineNoNewline, Text: " No newline at end of file"},
+	}
+	if !reflect.DeepEqual(hunk.Lines, expected) {
+		t.Errorf("Lines = %+v, want %+v", hunk.Lines, expected)
+	}
+
+	if added, removed := parsed.Files[0].Stats(); added != 2 || removed != 1 {
+		t.Errorf("Stats() = +%d -%d, want +2 -1", added, removed)
+	}
+	if parsed.String() != diff {
+		t.Errorf("String() did not reproduce the diff:\n%s", parsed.String())
+	}
+}
+
+func TestParseDiffGitPaths(t *testing.T) {
+	tests := []struct {
+		line    string
+		oldPath string
+		newPath string
+	}{
+		{"diff --git a/main.go b/main.go", "main.go", "main.go"},
+		{"diff --git a/with space.go b/with space.go", "with space.go", "with space.go"},
+		{"diff --git a/a b/c.go b/a b/c.go", "a b/c.go", "a b/c.go"},
+		{"diff --git a/old.go b/new.go", "old.go", "new.go"},
+		{`diff --git "a/t\303\244st \"q\".txt" "b/t\303\244st \"q\".txt"`, `täst "q".txt`, `täst "q".txt`},
+		{`diff --git a/plain.txt "b/t\303\244st.txt"`, "plain.txt", "täst.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			oldPath, newPath := parseDiffGitPaths(tt.line)
+			if oldPath != tt.oldPath || newPath != tt.newPath {
+				t.Errorf("parseDiffGitPaths() = %q, %q, want %q, %q", oldPath, newPath, tt.oldPath, tt.newPath)
+			}
+		})
+	}
+}
+
+// TestParseDiffRoundTrip parses real git output and checks that it renders back unchanged
+func TestParseDiffRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	runGit := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com", "-c", "core.quotePath=true"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git %s failed: %v", strings.Join(args, " "), err)
+		}
+		return string(out)
+	}
+	writeFile := func(name, content string, mode os.FileMode) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var longFile strings.Builder
+	longFile.WriteString("package main\n\nfunc main() {\n")
+	for i := 0; i < 20; i++ {
+		longFile.WriteString("\tstep()\n")
+	}
+	longFile.WriteString("}\n")
+
+	runGit("init", "-q")
+	writeFile("with space.go", longFile.String(), 0644)
+	writeFile(`tëst "q".txt`, "x\n", 0644)
+	writeFile("moved.txt", "one\ntwo\nthree\nfour\nfive\nsix\n", 0644)
+	writeFile("run.sh", "run\n", 0644)
+	writeFile("gone.txt", "gone\n", 0644)
+	runGit("add", "-A")
+	runGit("commit", "-q", "-m", "init")
+
+	writeFile("with space.go", strings.Replace(longFile.String(), "\tstep()\n}", "\tstep()\n\tdone()\n}", 1), 0644)
+	writeFile(`tëst "q".txt`, "y", 0644)
+	writeFile("run.sh", "run\n", 0755)
+	writeFile("bin.dat", "\x00\x01\x02", 0644)
+	runGit("mv", "moved.txt", "moved too.txt")
+	runGit("rm", "-q", "gone.txt")
+	runGit("add", "-A")
+
+	diff := runGit("diff", "--cached", "-M", "-C")
+	parsed := ParseDiff(diff)
+
+	if rendered := parsed.String(); rendered != diff {
+		t.Errorf("String() did not reproduce the diff:\n%s\nwant:\n%s", rendered, diff)
+	}
+
+	files := make(map[string]FileDiff)
+	var paths []string
+	for _, file := range parsed.Files {
+		files[file.Path] = file
+		paths = append(paths, file.Path)
+	}
+	expectedPaths := []string{"bin.dat", "gone.txt", "moved too.txt", "run.sh", `tëst "q".txt`, "with space.go"}
+	if !reflect.DeepEqual(paths, expectedPaths) {
+		t.Fatalf("Paths = %q, want %q", paths, expectedPaths)
+	}
+
+	if !files["bin.dat"].Binary || files["bin.dat"].ChangeType != ChangeAdded {
+		t.Errorf("Expected bin.dat to be an added binary file: %+v", files["bin.dat"])
+	}
+	if files["gone.txt"].ChangeType != ChangeDeleted {
+		t.Errorf("Expected gone.txt to be deleted: %+v", files["gone.txt"])
+	}
+	if moved := files["moved too.txt"]; moved.ChangeType != ChangeRenamed || moved.OldPath != "moved.txt" || moved.Similarity != 100 {
+		t.Errorf("Expected moved.txt to be renamed: %+v", moved)
+	}
+	if !files["run.sh"].ModeChanged() {
+		t.Errorf("Expected run.sh to have a mode change: %+v", files["run.sh"])
+	}
+
+	quoted := files[`tëst "q".txt`]
+	if len(quoted.Hunks) != 1 || quoted.Hunks[0].Lines[len(quoted.Hunks[0].Lines)-1].Kind != LineNoNewline {
+		t.Errorf("Expected the quoted file to end without a newline: %+v", quoted.Hunks)
+	}
+
+	spaced := files["with space.go"]
+	if len(spaced.Hunks) != 1 || spaced.Hunks[0].Section != "func main() {" {
+		t.Fatalf("Expected one hunk in func main: %+v", spaced.Hunks)
+	}
+	for _, line := range spaced.Hunks[0].Lines {
+		if line.Kind == LineAdded && (line.Text != "\tdone()" || line.NewNumber != 24) {
+			t.Errorf("Unexpected added line %+v", line)
+		}
+	}
+}
+
+func TestUnquoteGitPath(t *testing.T) {
+	tests := map[string]string{
+		"plain.txt":               "plain.txt",
+		`"a/t\303\244st.txt"`:     "a/täst.txt",
+		`"a/tab\there"`:           "a/tab\there",
+		`"a/quote \"q\" \\ back"`: `a/quote "q" \ back`,
+	}
+
+	for quoted, expected := range tests {
+		if result := unquoteGitPath(quoted); result != expected {
+			t.Errorf("unquoteGitPath(%q) = %q, want %q", quoted, result, expected)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ChangeType describes what happened to a file in a diff
+type ChangeType string
+
+const (
+	ChangeModified ChangeType = "modified"
+	ChangeAdded    ChangeType = "added"
+	ChangeDeleted  ChangeType = "deleted"
+	ChangeRenamed  ChangeType = "renamed"
+	ChangeCopied   ChangeType = "copied"
+)
+
+// submoduleMode is the git file mode of a submodule (gitlink) entry
+const submoduleMode = "160000"
+
+// FileDiff represents a single file's diff
+type FileDiff struct {
+	// Path is the new path of the file, or the old one for deleted files
+	Path string
+	// Header holds the lines before the first hunk, from "diff --git" to "+++"
+	Header string
+	Hunks  []Hunk
+
+	ChangeType ChangeType
+	OldPath    string
+	NewPath    string
+	// OldMode and NewMode are only set when the diff reports them, e.g. for new files or mode changes
+	OldMode string
+	NewMode string
+	// OldID and NewID are the (abbreviated) blob ids from the "index" line
+	OldID string
+	NewID string
+	// Similarity is the similarity index of renames and copies in percent
+	Similarity int
+	Binary     bool
+	// Submodule is set for changes to a submodule pointer
+	Submodule bool
+}
+
+var indexLineRegex = regexp.MustCompile(`^index ([0-9a-f]+)\.\.([0-9a-f]+)(?: (\d+))?$`)
+
+// String returns the file diff as it appears in the diff
+func (fd FileDiff) String() string {
+	var result strings.Builder
+	result.WriteString(fd.Header)
+	for _, hunk := range fd.Hunks {
+		result.WriteString(hunk.String())
+	}
+	return result.String()
+}
+
+// Stats returns the number of added and removed lines in the file
+func (fd FileDiff) Stats() (added, removed int) {
+	for _, hunk := range fd.Hunks {
+		hunkAdded, hunkRemoved := hunk.Stats()
+		added += hunkAdded
+		removed += hunkRemoved
+	}
+	return added, removed
+}
+
+// parseFileDiffHeader fills in the path and change details of a file diff from its header lines
+func parseFileDiffHeader(fd *FileDiff) {
+	fd.ChangeType = ChangeModified
+	lines := strings.Split(strings.TrimSuffix(fd.Header, "\n"), "\n")
+	fd.OldPath, fd.NewPath = parseDiffGitPaths(lines[0])
+
+	for _, line := range lines[1:] {
+		switch {
+		case strings.HasPrefix(line, "--- "):
+			if path := headerPath(strings.TrimPrefix(line, "--- "), true); path != "" {
+				fd.OldPath = path
+			}
+		case strings.HasPrefix(line, "+++ "):
+			if path := headerPath(strings.TrimPrefix(line, "+++ "), true); path != "" {
+				fd.NewPath = path
+			}
+		case strings.HasPrefix(line, "new file mode "):
+			fd.ChangeType = ChangeAdded
+			fd.NewMode = strings.TrimPrefix(line, "new file mode ")
+		case strings.HasPrefix(line, "deleted file mode "):
+			fd.ChangeType = ChangeDeleted
+			fd.OldMode = strings.TrimPrefix(line, "deleted file mode ")
+		case strings.HasPrefix(line, "old mode "):
+			fd.OldMode = strings.TrimPrefix(line, "old mode ")
+		case strings.HasPrefix(line, "new mode "):
+			fd.NewMode = strings.TrimPrefix(line, "new mode ")
+		case strings.HasPrefix(line, "rename from "):
+			fd.ChangeType = ChangeRenamed
+			fd.OldPath = headerPath(strings.TrimPrefix(line, "rename from "), false)
+		case strings.HasPrefix(line, "rename to "):
+			fd.NewPath = headerPath(strings.TrimPrefix(line, "rename to "), false)
+		case strings.HasPrefix(line, "copy from "):
+			fd.ChangeType = ChangeCopied
+			fd.OldPath = headerPath(strings.TrimPrefix(line, "copy from "), false)
+		case strings.HasPrefix(line, "copy to "):
+			fd.NewPath = headerPath(strings.TrimPrefix(line, "copy to "), false)
+		case strings.HasPrefix(line, "similarity index "):
+			fd.Similarity, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(line, "similarity index "), "%"))
+		case strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch":
+			fd.Binary = true
+		default:
+			if m := indexLineRegex.FindStringSubmatch(line); m != nil {
+				fd.OldID, fd.NewID = m[1], m[2]
+				if m[3] == submoduleMode {
+					fd.Submodule = true
+				}
+			}
+		}
+	}
+
+	if fd.OldMode == submoduleMode || fd.NewMode == submoduleMode {
+		fd.Submodule = true
+	}
+
+	fd.Path = fd.NewPath
+	if fd.ChangeType == ChangeDeleted || fd.Path == "" {
+		fd.Path = fd.OldPath
+	}
+}
+
+// HasTextChanges reports whether the diff contains hunks with changed lines
+func (fd FileDiff) HasTextChanges() bool {
+	return !fd.Binary && !fd.Submodule && len(fd.Hunks) > 0
+}
+
+// ModeChanged reports whether the file mode changed, e.g. when a script was made executable
+func (fd FileDiff) ModeChanged() bool {
+	return fd.ChangeType != ChangeAdded && fd.ChangeType != ChangeDeleted &&
+		fd.OldMode != "" && fd.NewMode != "" && fd.OldMode != fd.NewMode
+}
+
+// blobID returns the blob the diff changes the file to, or the old blob for deleted files
+func (fd FileDiff) blobID() string {
+	if fd.Submodule {
+		return ""
+	}
+	if isNullBlobID(fd.NewID) {
+		return fd.OldID
+	}
+	return fd.NewID
+}
+
+// Descriptor describes the change in a short phrase, e.g. "renamed a.go to b.go (95% similar)"
+func (fd FileDiff) Descriptor() string {
+	if fd.Submodule {
+		switch fd.ChangeType {
+		case ChangeAdded:
+			return fmt.Sprintf("added submodule %s at %s", fd.Path, fd.NewID)
+		case ChangeDeleted:
+			return fmt.Sprintf("removed submodule %s", fd.Path)
+		default:
+			return fmt.Sprintf("updated submodule %s from %s to %s", fd.Path, fd.OldID, fd.NewID)
+		}
+	}
+
+	kind := "file"
+	if fd.Binary {
+		kind = "binary file"
+	} else if len(fd.Hunks) == 0 && (fd.ChangeType == ChangeAdded || fd.ChangeType == ChangeDeleted) {
+		kind = "empty file"
+	}
+
+	var descriptor string
+	switch fd.ChangeType {
+	case ChangeRenamed, ChangeCopied:
+		descriptor = fmt.Sprintf("%s %s %s to %s", fd.ChangeType, kind, fd.OldPath, fd.NewPath)
+		if fd.Similarity > 0 {
+			descriptor += fmt.Sprintf(" (%d%% similar)", fd.Similarity)
+		}
+	default:
+		descriptor = fmt.Sprintf("%s %s %s", fd.ChangeType, kind, fd.Path)
+	}
+
+	if fd.ModeChanged() {
+		descriptor += fmt.Sprintf(", mode changed from %s to %s", fd.OldMode, fd.NewMode)
+	}
+	return descriptor
+}
+
+// PromptContent returns the diff as it is sent to the LLM. Changes without changed lines,
+// like binary files, pure renames, mode changes and submodule updates, are replaced by their
+// descriptor so the LLM does not have to interpret the raw header.
+func (fd FileDiff) PromptContent() string {
+	if fd.HasTextChanges() {
+		return fd.String()
+	}
+
+	header, _, _ := strings.Cut(fd.Header, "\n")
+	return header + "\n" + fd.Descriptor() + "\n"
+}
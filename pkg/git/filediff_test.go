@@ -0,0 +1,118 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDiffByFileChangeDetails(t *testing.T) {
+	diff := `diff --git a/old name.go b/new name.go
+similarity index 100%
+rename from old name.go
+rename to new name.go
+diff --git a/util.go b/helpers/util.go
+similarity index 92%
+rename from util.go
+rename to helpers/util.go
+index 1234567..89abcde 100644
+--- a/util.go
++++ b/helpers/util.go
+@@ -1,3 +1,3 @@
+ package helpers
+-func A() {}
++func B() {}
+diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..abcdef0
+Binary files /dev/null and b/logo.png differ
+diff --git a/run.sh b/run.sh
+old mode 100644
+new mode 100755
+diff --git a/vendor/lib b/vendor/lib
+index 1111111..2222222 160000
+--- a/vendor/lib
++++ b/vendor/lib
+@@ -1 +1 @@
+-Subproject commit 1111111111111111111111111111111111111111
++Subproject commit 2222222222222222222222222222222222222222
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+index abc1234..0000000
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+`
+
+	files := ParseDiffByFile(diff)
+	if len(files) != 6 {
+		t.Fatalf("Expected 6 files, got %d", len(files))
+	}
+
+	tests := []struct {
+		file       FileDiff
+		changeType ChangeType
+		path       string
+		descriptor string
+		blobID     string
+		textual    bool
+	}{
+		{files[0], ChangeRenamed, "new name.go", "renamed file old name.go to new name.go (100% similar)", "", false},
+		{files[1], ChangeRenamed, "helpers/util.go", "renamed file util.go to helpers/util.go (92% similar)", "89abcde", true},
+		{files[2], ChangeAdded, "logo.png", "added binary file logo.png", "abcdef0", false},
+		{files[3], ChangeModified, "run.sh", "modified file run.sh, mode changed from 100644 to 100755", "", false},
+		{files[4], ChangeModified, "vendor/lib", "updated submodule vendor/lib from 1111111 to 2222222", "", false},
+		{files[5], ChangeDeleted, "old.txt", "deleted file old.txt", "abc1234", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if tt.file.ChangeType != tt.changeType {
+				t.Errorf("ChangeType = %q, want %q", tt.file.ChangeType, tt.changeType)
+			}
+			if tt.file.Path != tt.path {
+				t.Errorf("Path = %q, want %q", tt.file.Path, tt.path)
+			}
+			if descriptor := tt.file.Descriptor(); descriptor != tt.descriptor {
+				t.Errorf("Descriptor() = %q, want %q", descriptor, tt.descriptor)
+			}
+			if blobID := tt.file.blobID(); blobID != tt.blobID {
+				t.Errorf("blobID() = %q, want %q", blobID, tt.blobID)
+			}
+			if textual := tt.file.HasTextChanges(); textual != tt.textual {
+				t.Errorf("HasTextChanges() = %v, want %v", textual, tt.textual)
+			}
+		})
+	}
+
+	if !files[2].Binary || files[1].Binary {
+		t.Errorf("Expected only logo.png to be binary")
+	}
+	if !files[4].Submodule {
+		t.Errorf("Expected vendor/lib to be a submodule")
+	}
+	if files[1].OldPath != "util.go" || files[1].NewPath != "helpers/util.go" || files[1].Similarity != 92 {
+		t.Errorf("Unexpected rename details: %+v", files[1])
+	}
+}
+
+func TestFileDiffPromptContent(t *testing.T) {
+	files := ParseDiffByFile(`diff --git a/logo.png b/logo.png
+index 1234567..abcdef0 100644
+Binary files a/logo.png and b/logo.png differ
+diff --git a/main.go b/main.go
+index 1234567..abcdef0 100644
+--- a/main.go
++++ b/main.go
+@@ -1 +1 @@
+-package a
++package main
+`)
+
+	if content := files[0].PromptContent(); content != "diff --git a/logo.png b/logo.png\nmodified binary file logo.png\n" {
+		t.Errorf("Expected binary file to be described, got %q", content)
+	}
+	if content := files[1].PromptContent(); content != files[1].String() || !strings.Contains(content, "+package main") {
+		t.Errorf("Expected text changes to be kept, got %q", content)
+	}
+}
@@ -2,10 +2,18 @@ package git
 
 import (
 	"bufio"
+	"bytes"
+	"fmt"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
+
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/logger"
 )
 
 // GeneratedFileDetector provides methods to detect generated files
@@ -37,6 +45,11 @@ type GeneratedFileDetector struct {
 	grpcGeneratedHeaderRegex   *regexp.Regexp
 	dartGeneratedHeaderRegex   *regexp.Regexp
 	haxeGeneratedHeaderRegex   *regexp.Regexp
+
+	// attributes are the linguist attributes from .gitattributes, which take precedence over the heuristics
+	attributes map[string]linguistAttributes
+	// blobIDs are the blobs the content-based heuristics read for each path
+	blobIDs map[string]string
 }
 
 // NewGeneratedFileDetector creates a new instance of the generated file detector
@@ -98,8 +111,25 @@ func NewGeneratedFileDetector() *GeneratedFileDetector {
 	return detector
 }
 
-// IsGenerated checks if a file is likely generated based on its path and content
+// LoadAttributes looks up the linguist-generated, linguist-vendored and linguist-documentation
+// attributes of the given paths, so that IsGenerated honors them. With cached, the
+// .gitattributes files are read from the index, as for staged changes.
+func (d *GeneratedFileDetector) LoadAttributes(paths []string, cached bool) {
+	attributes, err := getLinguistAttributes(paths, cached)
+	if err != nil {
+		logger.Debug("Could not read git attributes: %v", err)
+		return
+	}
+	d.attributes = attributes
+}
+
+// IsGenerated checks if a file is likely generated based on its attributes, path and content
 func (d *GeneratedFileDetector) IsGenerated(filePath string) bool {
+	// Explicit .gitattributes override the heuristics in both directions
+	if filtered, ok := d.attributes[filePath].override(); ok {
+		return filtered
+	}
+
 	// Extract filename, extension, and name components
 	filename := filepath.Base(filePath)
 	ext := strings.ToLower(filepath.Ext(filePath))
@@ -202,13 +232,12 @@ func (d *GeneratedFileDetector) IsGenerated(filePath string) bool {
 		return true
 	}
 
-	// If the file exists, scan the content for generated markers
-	if d.hasGeneratedContent(filePath) {
+	// Scan the content for generated markers and minified code
+	traits := d.contentTraitsOf(filePath)
+	if traits.generatedHeader {
 		return true
 	}
-
-	// If the file is minified (very long lines)
-	if d.isMinified(filePath) {
+	if isMinifiedCandidate(filePath) && traits.minified {
 		return true
 	}
 
@@ -301,16 +330,111 @@ func isGeneratedFilename(filename string) bool {
 	return false
 }
 
-// hasGeneratedContent checks if file content indicates it's generated
-func (d *GeneratedFileDetector) hasGeneratedContent(filePath string) bool {
-	file, err := os.Open(filePath)
+// contentTraits are the results of the content-based heuristics for a blob
+type contentTraits struct {
+	generatedHeader bool
+	minified        bool
+}
+
+var (
+	// contentCache holds the content traits per blob id, so blobs are only read once
+	contentCache   = make(map[string]contentTraits)
+	contentCacheMu sync.Mutex
+)
+
+// LoadContents reads the contents of the given paths for the content-based heuristics, using
+// a single git cat-file process for all of them. blobIDs maps each path to the blob to read;
+// paths with an empty id are looked up in the index. Results are cached per blob id.
+func (d *GeneratedFileDetector) LoadContents(blobIDs map[string]string) {
+	ids := make(map[string]string, len(blobIDs))
+	var unknown []string
+	for path, id := range blobIDs {
+		if id == "" {
+			unknown = append(unknown, path)
+		} else {
+			ids[path] = id
+		}
+	}
+	if len(unknown) > 0 {
+		indexIDs, err := getIndexBlobIDs(unknown)
+		if err != nil {
+			logger.Debug("Could not look up index entries: %v", err)
+		}
+		for path, id := range indexIDs {
+			ids[path] = id
+		}
+	}
+
+	var missing []string
+	seen := make(map[string]bool)
+	contentCacheMu.Lock()
+	for _, id := range ids {
+		if _, ok := contentCache[id]; !ok && !seen[id] {
+			missing = append(missing, id)
+			seen[id] = true
+		}
+	}
+	contentCacheMu.Unlock()
+
+	blobs, err := readBlobs(missing)
 	if err != nil {
-		return false
+		logger.Debug("Could not read blobs: %v", err)
+	}
+
+	contentCacheMu.Lock()
+	for id, data := range blobs {
+		contentCache[id] = d.analyzeContent(data)
+	}
+	contentCacheMu.Unlock()
+
+	d.blobIDs = ids
+}
+
+// contentTraitsOf returns the content traits of a file from its loaded blob. Files without a
+// readable blob, such as unstaged changes, are read from the working tree instead.
+func (d *GeneratedFileDetector) contentTraitsOf(filePath string) contentTraits {
+	if id, ok := d.blobIDs[filePath]; ok {
+		contentCacheMu.Lock()
+		traits, cached := contentCache[id]
+		contentCacheMu.Unlock()
+		if cached {
+			return traits
+		}
+	}
+
+	root, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return contentTraits{}
+	}
+	data, err := readFileSample(filepath.Join(strings.TrimSpace(string(root)), filePath))
+	if err != nil {
+		return contentTraits{}
+	}
+	return d.analyzeContent(data)
+}
+
+// readFileSample reads the start of a file, like readBlobs does for blobs
+func readFileSample(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
 	}
 	defer file.Close()
+	return io.ReadAll(io.LimitReader(file, maxBlobSample))
+}
 
+// analyzeContent runs the content-based heuristics on the start of a file
+func (d *GeneratedFileDetector) analyzeContent(data []byte) contentTraits {
+	return contentTraits{
+		generatedHeader: d.hasGeneratedContent(data),
+		minified:        isMinified(data),
+	}
+}
+
+// hasGeneratedContent checks if file content indicates it's generated
+func (d *GeneratedFileDetector) hasGeneratedContent(data []byte) bool {
 	// Scan the first 40 lines of the file to check for generated markers
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 	lineCount := 0
 	maxLines := 40
 
@@ -346,20 +470,15 @@ func (d *GeneratedFileDetector) hasGeneratedContent(filePath string) bool {
 	return false
 }
 
-// isMinified checks if a file is likely minified (very long lines)
-func (d *GeneratedFileDetector) isMinified(filePath string) bool {
+// isMinifiedCandidate checks if a file has an extension that is commonly minified
+func isMinifiedCandidate(filePath string) bool {
 	ext := strings.ToLower(filepath.Ext(filePath))
-	if ext != ".js" && ext != ".css" && ext != ".html" && ext != ".json" {
-		return false
-	}
-
-	file, err := os.Open(filePath)
-	if err != nil {
-		return false
-	}
-	defer file.Close()
+	return ext == ".js" || ext == ".css" || ext == ".html" || ext == ".json"
+}
 
-	scanner := bufio.NewScanner(file)
+// isMinified checks if file content is likely minified (very long lines)
+func isMinified(data []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 	lineCount := 0
 	totalLength := 0
 	longLineCount := 0
@@ -401,6 +520,15 @@ func (d *GeneratedFileDetector) isMinified(filePath string) bool {
 // FilterGeneratedFiles removes generated files from a list of file paths
 func FilterGeneratedFiles(filePaths []string) []string {
 	detector := NewGeneratedFileDetector()
+	detector.LoadAttributes(filePaths, true)
+
+	// Read the staged contents of all files at once
+	blobIDs := make(map[string]string, len(filePaths))
+	for _, path := range filePaths {
+		blobIDs[path] = ""
+	}
+	detector.LoadContents(blobIDs)
+
 	var result []string
 
 	for _, path := range filePaths {
@@ -412,52 +540,97 @@ func FilterGeneratedFiles(filePaths []string) []string {
 	return result
 }
 
-// FilterGeneratedDiff filters out changes to generated files from a git diff
-func FilterGeneratedDiff(diff string) string {
+// FilterGeneratedDiff filters out changes to generated files and paths excluded by
+// .git-ai-ignore or the exclude_paths setting from a git diff. Binary files, pure renames,
+// mode changes and submodule updates are replaced by a short description.
+func FilterGeneratedDiff(cfg config.Config, diff string) string {
+	return filterGeneratedDiff(cfg, diff, false)
+}
+
+// filterGeneratedDiff implements FilterGeneratedDiff. With cachedAttributes, .gitattributes
+// overrides are read from the index, for diffs of staged changes.
+func filterGeneratedDiff(cfg config.Config, diff string, cachedAttributes bool) string {
 	if diff == "" {
 		return ""
 	}
 
 	// Split the diff into files
-	diffFiles := strings.Split(diff, "diff --git ")
-	if len(diffFiles) <= 1 {
+	parsed := ParseDiff(diff)
+	fileDiffs := parsed.Files
+	if len(fileDiffs) == 0 {
 		return diff
 	}
 
+	filePaths := make([]string, len(fileDiffs))
+	blobIDs := make(map[string]string)
+	for i, fileDiff := range fileDiffs {
+		filePaths[i] = fileDiff.Path
+		if fileDiff.Path != "" {
+			blobIDs[fileDiff.Path] = fileDiff.blobID()
+		}
+	}
+
+	// Read the contents of all files at once, from the blobs the diff refers to
 	detector := NewGeneratedFileDetector()
+	detector.LoadAttributes(filePaths, cachedAttributes)
+	detector.LoadContents(blobIDs)
+	excluded := loadExclusions(cfg)
 	var filteredDiff strings.Builder
-
-	// The first element is empty or header info
-	if diffFiles[0] != "" {
-		filteredDiff.WriteString(diffFiles[0])
-	}
+	var omitted []string
 
 	// Process each file diff
-	for i := 1; i < len(diffFiles); i++ {
-		fileDiff := diffFiles[i]
-		// Extract the file path - typically in the format "a/path/file.ext b/path/file.ext"
-		pathLine := strings.Split(fileDiff, "\n")[0]
-		filePath := ""
-
-		// Extract the file path after "a/"
-		parts := strings.Fields(pathLine)
-		if len(parts) >= 1 {
-			// Get path after "a/"
-			filePath = strings.TrimPrefix(parts[0], "a/")
+	for _, fileDiff := range fileDiffs {
+		switch {
+		case fileDiff.Path == "":
+			// If we couldn't parse the path, include it
+		case excluded.matchFile(fileDiff.OldPath, fileDiff.NewPath):
+			// Excluded files are only named when mention_excluded_paths is set
+			if excluded.mention {
+				omitted = append(omitted, diffStatLine(fileDiff, "excluded"))
+			}
+			continue
+		case detector.IsGenerated(fileDiff.Path):
+			omitted = append(omitted, diffStatLine(fileDiff, "generated"))
+			continue
 		}
 
-		// If we couldn't parse the path or it's not a generated file, include it
-		if filePath == "" || !detector.IsGenerated(filePath) {
-			filteredDiff.WriteString("diff --git ")
-			filteredDiff.WriteString(fileDiff)
+		filteredDiff.WriteString(fileDiff.PromptContent())
+	}
+
+	// Anything before the first file is kept, followed by the stat lines of the omitted
+	// files so that a change to only generated files still has something to describe
+	var result strings.Builder
+	preamble := parsed.Preamble
+	result.WriteString(preamble)
+	if len(omitted) > 0 {
+		if preamble != "" && !strings.HasSuffix(preamble, "\n") {
+			result.WriteString("\n")
 		}
+		result.WriteString(omittedFilesHeader + "\n")
+		for _, line := range omitted {
+			result.WriteString(line + "\n")
+		}
+	}
+	result.WriteString(filteredDiff.String())
+
+	return result.String()
+}
+
+// omittedFilesHeader introduces the stat lines of files left out of a filtered diff
+const omittedFilesHeader = "Files with contents omitted:"
+
+// diffStatLine summarizes a file diff as " path | +added -removed reason"
+func diffStatLine(fileDiff FileDiff, reason string) string {
+	if fileDiff.Binary {
+		return fmt.Sprintf(" %s | binary %s", fileDiff.Path, reason)
 	}
 
-	return filteredDiff.String()
+	added, removed := fileDiff.Stats()
+	return fmt.Sprintf(" %s | +%d -%d %s", fileDiff.Path, added, removed, reason)
 }
 
 // GetStagedDiffFiltered returns the diff of staged changes, filtering out generated files
-func GetStagedDiffFiltered() string {
+func GetStagedDiffFiltered(cfg config.Config) string {
 	diff := GetStagedDiff()
-	return FilterGeneratedDiff(diff)
+	return filterGeneratedDiff(cfg, diff, true)
 }
@@ -3,6 +3,8 @@ package git
 import (
 	"strings"
 	"testing"
+
+	"github.com/recrsn/git-ai/pkg/config"
 )
 
 func TestIsGenerated(t *testing.T) {
@@ -119,7 +121,7 @@ func TestIsGenerated(t *testing.T) {
 	}
 }
 
-// TestFilterGeneratedDiff tests that diffs for generated files are filtered out
+// TestFilterGeneratedDiff tests that diffs for generated files are replaced by stat lines
 func TestFilterGeneratedDiff(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -173,7 +175,9 @@ index 1234567..abcdef0 100644
 -require github.com/pkg/errors v0.9.0
 +require github.com/pkg/errors v0.9.1
  go 1.16`,
-			expected: `Some header info`,
+			expected: `Some header info
+Files with contents omitted:
+ go.sum | +1 -1 generated`,
 		},
 		{
 			name: "Single generated file - lock file",
@@ -189,7 +193,9 @@ index 1234567..abcdef0 100644
 -  version "4.17.20"
 +lodash@^4.17.21:
    version "4.17.21"`,
-			expected: `Some header info`,
+			expected: `Some header info
+Files with contents omitted:
+ yarn.lock | +1 -2 generated`,
 		},
 		{
 			name: "Single generated file - minified",
@@ -203,7 +209,9 @@ index 1234567..abcdef0 100644
 -//# sourceMappingURL=app.min.js.map
 +!function(e,t){"object"==typeof exports&&"undefined"!=typeof module?module.exports=t():"function"==typeof define&&define.amd?define(t):(e="undefined"!=typeof globalThis?globalThis:e||self).App=t()}(this,function(){return function(){console.log("updated")}});
 +//# sourceMappingURL=app.min.js.map`,
-			expected: `Some header info`,
+			expected: `Some header info
+Files with contents omitted:
+ dist/app.min.js | +2 -2 generated`,
 		},
 		{
 			name: "Mixed files",
@@ -228,6 +236,8 @@ index 1234567..abcdef0 100644
  	fmt.Println("Hello")
  }`,
 			expected: `Some header info
+Files with contents omitted:
+ go.sum | +1 -1 generated
 diff --git a/src/main.go b/src/main.go
 index 1234567..abcdef0 100644
 --- a/src/main.go
@@ -239,11 +249,30 @@ index 1234567..abcdef0 100644
  	fmt.Println("Hello")
  }`,
 		},
+		{
+			name: "Only generated files",
+			diff: `diff --git a/api/service.pb.go b/api/service.pb.go
+index 1234567..abcdef0 100644
+--- a/api/service.pb.go
++++ b/api/service.pb.go
+@@ -1,3 +1,4 @@
+ package api
++// Regenerated
+-var x = 1
++var x = 2
+diff --git a/assets/logo.min.js b/assets/logo.min.js
+new file mode 100644
+index 0000000..abcdef0
+Binary files /dev/null and b/assets/logo.min.js differ`,
+			expected: `Files with contents omitted:
+ api/service.pb.go | +2 -1 generated
+ assets/logo.min.js | binary generated`,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := FilterGeneratedDiff(tt.diff)
+			result := FilterGeneratedDiff(config.Config{}, tt.diff)
 
 			// Normalize line endings and trim spaces for comparison
 			result = strings.TrimSpace(strings.ReplaceAll(result, "\r\n", "\n"))
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
 
+	"github.com/recrsn/git-ai/pkg/config"
 	"github.com/recrsn/git-ai/pkg/logger"
 )
 
@@ -21,7 +23,8 @@ func HasStagedChanges() bool {
 
 // GetStagedDiff returns the diff of staged changes
 func GetStagedDiff() string {
-	cmd := exec.Command("git", "diff", "--cached")
+	// Detect renames and copies so they show up as such instead of a deletion and an addition
+	cmd := exec.Command("git", "diff", "--cached", "-M", "-C")
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	err := cmd.Run()
@@ -58,8 +61,8 @@ func GetRecentCommits() string {
 	return out.String()
 }
 
-// GetChangedFiles returns a list of staged files
-func GetChangedFiles() string {
+// GetChangedFiles returns a list of staged files, leaving out excluded paths
+func GetChangedFiles(cfg config.Config) string {
 	cmd := exec.Command("git", "diff", "--cached", "--name-only")
 	var out bytes.Buffer
 	cmd.Stdout = &out
@@ -68,7 +71,7 @@ func GetChangedFiles() string {
 		logger.Error("Error getting changed files: %v", err)
 		return ""
 	}
-	return out.String()
+	return FilterExcludedFiles(cfg, out.String())
 }
 
 // CreateCommit creates a git commit with the given message
@@ -311,3 +314,219 @@ func SaveConfigPreference(key, value string) {
 		logger.Warn("Could not save %s preference: %v", key, err)
 	}
 }
+
+// HasHead checks if the repository has at least one commit
+func HasHead() bool {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", "HEAD")
+	return cmd.Run() == nil
+}
+
+// GetStagedPatch returns the staged changes as a patch that can be re-applied with git apply,
+// including binary contents and detected renames and copies
+func GetStagedPatch() string {
+	cmd := exec.Command("git", "diff", "--cached", "--binary", "-M", "-C")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error getting staged patch: %v", err)
+		return ""
+	}
+	return out.String()
+}
+
+// WriteIndexTree writes the current index to a tree object and returns its hash
+func WriteIndexTree() (string, error) {
+	cmd := exec.Command("git", "write-tree")
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error writing index tree: %v: %s", err, stderr.String())
+		return "", fmt.Errorf("error writing index tree: %v: %s", err, stderr.String())
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// ReadTreeIntoIndex replaces the index with the contents of the given tree
+func ReadTreeIntoIndex(tree string) error {
+	cmd := exec.Command("git", "read-tree", tree)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error reading tree %s into index: %v: %s", tree, err, stderr.String())
+		return fmt.Errorf("error reading tree %s into index: %v: %s", tree, err, stderr.String())
+	}
+	return nil
+}
+
+// ResetIndex unstages all changes, leaving the working tree untouched
+func ResetIndex() error {
+	args := []string{"reset", "--quiet"}
+	if !HasHead() {
+		// Nothing to reset to before the first commit
+		args = []string{"read-tree", "--empty"}
+	}
+	cmd := exec.Command("git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error resetting index: %v: %s", err, stderr.String())
+		return fmt.Errorf("error resetting index: %v: %s", err, stderr.String())
+	}
+	return nil
+}
+
+// ApplyPatchToIndex stages the given patch without touching the working tree
+func ApplyPatchToIndex(patch string) error {
+	cmd := exec.Command("git", "apply", "--cached", "-")
+	cmd.Stdin = strings.NewReader(patch)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error applying patch to index: %v: %s", err, stderr.String())
+		return fmt.Errorf("error applying patch to index: %v: %s", err, stderr.String())
+	}
+	return nil
+}
+
+// GetRepoRoot returns the absolute path of the top-level directory of the working tree
+func GetRepoRoot() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error getting repository root: %v", err)
+		return "", fmt.Errorf("error getting repository root: %v", err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// GetHooksDir returns the absolute path of the directory git runs hooks from,
+// respecting core.hooksPath
+func GetHooksDir() (string, error) {
+	hooksPath, err := GetConfig("core.hooksPath")
+	if err == nil && hooksPath != "" {
+		if strings.HasPrefix(hooksPath, "~/") {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return "", fmt.Errorf("failed to get user home directory: %w", err)
+			}
+			hooksPath = filepath.Join(homeDir, hooksPath[2:])
+		}
+		if filepath.IsAbs(hooksPath) {
+			return hooksPath, nil
+		}
+
+		// Relative hook paths are resolved against the root of the working tree
+		root, err := GetRepoRoot()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(root, hooksPath), nil
+	}
+
+	cmd := exec.Command("git", "rev-parse", "--git-path", "hooks")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err = cmd.Run()
+	if err != nil {
+		logger.Error("Error getting hooks directory: %v", err)
+		return "", fmt.Errorf("error getting hooks directory: %v", err)
+	}
+	return filepath.Abs(strings.TrimSpace(out.String()))
+}
+
+// GetConflictedFiles returns the paths of files with unresolved merge conflicts,
+// relative to the repository root
+func GetConflictedFiles() ([]string, error) {
+	cmd := exec.Command("git", "diff", "--name-only", "--diff-filter=U")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error getting conflicted files: %v", err)
+		return nil, fmt.Errorf("error getting conflicted files: %v", err)
+	}
+
+	var result []string
+	for _, file := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if file != "" {
+			result = append(result, file)
+		}
+	}
+	return result, nil
+}
+
+// GetDiff3Conflict re-merges the index stages of a conflicted file and returns the
+// content with diff3-style conflict markers, without touching the working tree
+func GetDiff3Conflict(path string) (string, error) {
+	tmpDir, err := os.MkdirTemp("", "git-ai-merge-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Stage 1 is the common ancestor, 2 is ours and 3 is theirs
+	stageFiles := make([]string, 3)
+	for i, stage := range []string{"2", "1", "3"} {
+		cmd := exec.Command("git", "show", ":"+stage+":"+path)
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		if err := cmd.Run(); err != nil {
+			// A missing stage (e.g. both sides added the file) is merged as empty
+			logger.Debug("No stage %s for %s: %v", stage, path, err)
+		}
+
+		stageFiles[i] = filepath.Join(tmpDir, "stage"+stage)
+		if err := os.WriteFile(stageFiles[i], out.Bytes(), 0600); err != nil {
+			return "", fmt.Errorf("failed to write stage %s: %w", stage, err)
+		}
+	}
+
+	cmd := exec.Command("git", "merge-file", "-p", "--diff3",
+		"-L", "ours", "-L", "base", "-L", "theirs",
+		stageFiles[0], stageFiles[1], stageFiles[2])
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err != nil {
+		// merge-file exits with the number of conflicts, which is expected here
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() < 0 || exitErr.ExitCode() > 127 {
+			return "", fmt.Errorf("error merging %s: %v: %s", path, err, stderr.String())
+		}
+	}
+	return out.String(), nil
+}
+
+// StageFile adds the given file to the index
+func StageFile(path string) error {
+	cmd := exec.Command("git", "add", "--", path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error staging %s: %v: %s", path, err, stderr.String())
+		return fmt.Errorf("error staging %s: %v: %s", path, err, stderr.String())
+	}
+	return nil
+}
+
+// GetUntrackedFiles returns a list of untracked files that are not ignored
+func GetUntrackedFiles() string {
+	cmd := exec.Command("git", "ls-files", "--others", "--exclude-standard")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error getting untracked files: %v", err)
+		return ""
+	}
+	return out.String()
+}
@@ -0,0 +1,329 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/logger"
+)
+
+// CommitInfo holds the metadata needed to recreate a commit
+type CommitInfo struct {
+	Hash        string
+	Tree        string
+	Parents     []string
+	AuthorName  string
+	AuthorEmail string
+	// AuthorDate is in git's raw format ("<unix timestamp> <timezone>")
+	AuthorDate string
+	Message    string
+}
+
+// ResolveCommit resolves a revision to a full commit hash
+func ResolveCommit(rev string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	if err != nil {
+		return "", fmt.Errorf("unknown revision %s", rev)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// GetCommitsInRange returns the hashes of commits reachable from tip but not from base, oldest first
+func GetCommitsInRange(base, tip string) ([]string, error) {
+	return revList("--reverse", "--topo-order", base+".."+tip)
+}
+
+// HasMergeCommits checks if there are merge commits reachable from tip but not from base
+func HasMergeCommits(base, tip string) (bool, error) {
+	merges, err := revList("--min-parents=2", base+".."+tip)
+	if err != nil {
+		return false, err
+	}
+	return len(merges) > 0, nil
+}
+
+// GetUnpushedCommits returns the commits between base and tip that are not on any remote-tracking branch
+func GetUnpushedCommits(base, tip string) ([]string, error) {
+	return revList(base+".."+tip, "--not", "--remotes")
+}
+
+// revList runs git rev-list with the given arguments and returns the listed hashes
+func revList(args ...string) ([]string, error) {
+	cmd := exec.Command("git", append([]string{"rev-list"}, args...)...)
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error listing commits: %v: %s", err, stderr.String())
+		return nil, fmt.Errorf("error listing commits: %v: %s", err, stderr.String())
+	}
+
+	var result []string
+	for _, hash := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if hash != "" {
+			result = append(result, hash)
+		}
+	}
+	return result, nil
+}
+
+// GetCommitInfo returns the tree, parents, author and message of a commit
+func GetCommitInfo(rev string) (CommitInfo, error) {
+	cmd := exec.Command("git", "log", "-1", "--date=raw", "--format=%H%x00%T%x00%P%x00%an%x00%ae%x00%ad%x00%B", rev)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error getting commit %s: %v", rev, err)
+		return CommitInfo{}, fmt.Errorf("error getting commit %s: %v", rev, err)
+	}
+
+	fields := strings.SplitN(out.String(), "\x00", 7)
+	if len(fields) != 7 {
+		return CommitInfo{}, fmt.Errorf("unexpected output for commit %s", rev)
+	}
+
+	return CommitInfo{
+		Hash:        fields[0],
+		Tree:        fields[1],
+		Parents:     strings.Fields(fields[2]),
+		AuthorName:  fields[3],
+		AuthorEmail: fields[4],
+		AuthorDate:  fields[5],
+		Message:     strings.TrimSpace(fields[6]),
+	}, nil
+}
+
+// GetCommitDiff returns the diff introduced by a commit
+func GetCommitDiff(rev string) string {
+	// Detect renames and copies so they show up as such instead of a deletion and an addition
+	cmd := exec.Command("git", "show", "--format=", "--patch", "-M", "-C", rev)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error getting diff of commit %s: %v", rev, err)
+		return ""
+	}
+	return out.String()
+}
+
+// GetCommitChangedFiles returns the list of files changed by a commit, leaving out excluded paths
+func GetCommitChangedFiles(cfg config.Config, rev string) string {
+	cmd := exec.Command("git", "show", "--format=", "--name-only", "-M", "-C", rev)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error getting changed files of commit %s: %v", rev, err)
+		return ""
+	}
+	return FilterExcludedFiles(cfg, out.String())
+}
+
+// GetRecentCommitsBefore returns the recent commit messages leading up to, but not including, a commit
+func GetRecentCommitsBefore(rev string) string {
+	cmd := exec.Command("git", "log", "-n", "5", "--pretty=format:%s", rev+"^")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	if err != nil {
+		// The first commit has no history
+		logger.Debug("Error getting commits before %s: %v", rev, err)
+		return ""
+	}
+	return out.String()
+}
+
+// CreateCommitFromInfo writes a new commit object with the tree and author of an existing
+// commit, the given parent and message, and returns its hash. HEAD is not updated.
+func CreateCommitFromInfo(info CommitInfo, parent, message string) (string, error) {
+	args := []string{"commit-tree", info.Tree, "-F", "-"}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+info.AuthorName,
+		"GIT_AUTHOR_EMAIL="+info.AuthorEmail,
+		"GIT_AUTHOR_DATE="+info.AuthorDate,
+	)
+	cmd.Stdin = strings.NewReader(message)
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error creating commit object: %v: %s", err, stderr.String())
+		return "", fmt.Errorf("error creating commit object: %v: %s", err, stderr.String())
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// UpdateHead moves HEAD (and the branch it points to) from oldHash to newHash,
+// failing if HEAD no longer points to oldHash
+func UpdateHead(newHash, oldHash, reason string) error {
+	cmd := exec.Command("git", "update-ref", "-m", reason, "HEAD", newHash, oldHash)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error updating HEAD: %v: %s", err, stderr.String())
+		return fmt.Errorf("error updating HEAD: %v: %s", err, stderr.String())
+	}
+	return nil
+}
+
+// GetDefaultBaseBranch guesses the branch feature branches are based on: the remote's
+// default branch if known, otherwise a local main or master branch
+func GetDefaultBaseBranch() (string, error) {
+	cmd := exec.Command("git", "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err == nil {
+		if branch := strings.TrimSpace(out.String()); branch != "" {
+			return branch, nil
+		}
+	}
+
+	for _, candidate := range []string{"main", "master"} {
+		if _, err := ResolveCommit("refs/heads/" + candidate); err == nil {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not determine the base branch, please specify it explicitly")
+}
+
+// GetMergeBase returns the best common ancestor of two commits
+func GetMergeBase(a, b string) (string, error) {
+	cmd := exec.Command("git", "merge-base", a, b)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error getting merge base of %s and %s: %v", a, b, err)
+		return "", fmt.Errorf("error getting merge base of %s and %s: %v", a, b, err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// GetDiffBetween returns the diff between two commits
+func GetDiffBetween(base, tip string) string {
+	// Detect renames and copies so they show up as such instead of a deletion and an addition
+	cmd := exec.Command("git", "diff", "-M", "-C", base, tip)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error getting diff between %s and %s: %v", base, tip, err)
+		return ""
+	}
+	return out.String()
+}
+
+// GetChangedFilesBetween returns the list of files changed between two commits, leaving out excluded paths
+func GetChangedFilesBetween(cfg config.Config, base, tip string) string {
+	cmd := exec.Command("git", "diff", "--name-only", "-M", "-C", base, tip)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error getting changed files between %s and %s: %v", base, tip, err)
+		return ""
+	}
+	return FilterExcludedFiles(cfg, out.String())
+}
+
+// GetCommitMessagesInRange returns the full messages of commits reachable from tip but not from base, oldest first.
+// An empty base selects the whole history of tip.
+func GetCommitMessagesInRange(base, tip string) ([]string, error) {
+	rangeSpec := tip
+	if base != "" {
+		rangeSpec = base + ".." + tip
+	}
+	cmd := exec.Command("git", "log", "--reverse", "--format=%B%x00", rangeSpec)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error getting commit messages: %v", err)
+		return nil, fmt.Errorf("error getting commit messages: %v", err)
+	}
+
+	var result []string
+	for _, message := range strings.Split(out.String(), "\x00") {
+		if message = strings.TrimSpace(message); message != "" {
+			result = append(result, message)
+		}
+	}
+	return result, nil
+}
+
+// SoftReset moves HEAD to the given commit, keeping all changes staged
+func SoftReset(rev string) error {
+	cmd := exec.Command("git", "reset", "--soft", rev)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error resetting to %s: %v: %s", rev, err, stderr.String())
+		return fmt.Errorf("error resetting to %s: %v: %s", rev, err, stderr.String())
+	}
+	return nil
+}
+
+// blameHeaderRegex matches the header line git blame --porcelain writes for every blamed
+// line, starting with a SHA-1 or SHA-256 commit hash
+var blameHeaderRegex = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64}) \d+ \d+`)
+
+// BlameLines returns the hash of the commit that last changed each line of the given
+// 1-based inclusive line ranges of a file, as of rev
+func BlameLines(rev, path string, ranges [][2]int) ([]string, error) {
+	args := []string{"blame", "--porcelain"}
+	for _, r := range ranges {
+		args = append(args, "-L", fmt.Sprintf("%d,%d", r[0], r[1]))
+	}
+	args = append(args, rev, "--", path)
+
+	cmd := exec.Command("git", args...)
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		logger.Debug("Error running blame on %s: %v: %s", path, err, stderr.String())
+		return nil, fmt.Errorf("error running blame on %s: %v: %s", path, err, stderr.String())
+	}
+
+	var hashes []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if m := blameHeaderRegex.FindStringSubmatch(line); m != nil {
+			hashes = append(hashes, m[1])
+		}
+	}
+	return hashes, nil
+}
+
+// CreateFixupCommit commits the staged changes as a "fixup!" commit for the target commit
+func CreateFixupCommit(target string) error {
+	cmd := exec.Command("git", "commit", "--fixup="+target)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error creating fixup commit: %v: %s", err, stderr.String())
+		return fmt.Errorf("error creating fixup commit: %v: %s", err, stderr.String())
+	}
+	return nil
+}
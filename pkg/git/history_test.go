@@ -0,0 +1,55 @@
+package git
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCommitDiffsDetectRenames(t *testing.T) {
+	dir := initTestRepo(t)
+	// Rename detection must not depend on the user's configuration
+	runTestGit(t, dir, "config", "diff.renames", "false")
+	content := strings.Repeat("a line that stays the same\n", 20)
+	writeTestFile(t, dir, "old.txt", content)
+	runTestGit(t, dir, "add", "-A")
+	runTestGit(t, dir, "commit", "-q", "-m", "Initial commit")
+	runTestGit(t, dir, "mv", "old.txt", "new.txt")
+	writeTestFile(t, dir, "new.txt", content+"one more line\n")
+	runTestGit(t, dir, "add", "-A")
+	runTestGit(t, dir, "commit", "-q", "-m", "Rename and extend")
+	t.Chdir(dir)
+
+	for name, diff := range map[string]string{
+		"GetCommitDiff":  GetCommitDiff("HEAD"),
+		"GetDiffBetween": GetDiffBetween("HEAD~1", "HEAD"),
+	} {
+		file := ParseDiff(diff).Files
+		if len(file) != 1 || file[0].ChangeType != ChangeRenamed || file[0].OldPath != "old.txt" {
+			t.Errorf("Expected %s to show a rename of old.txt, got:\n%s", name, diff)
+		}
+	}
+}
+
+func TestBlameLinesSHA256(t *testing.T) {
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", "--object-format=sha256", dir).CombinedOutput(); err != nil {
+		t.Skipf("git does not support SHA-256 repositories: %v: %s", err, out)
+	}
+	runTestGit(t, dir, "config", "user.name", "Test")
+	runTestGit(t, dir, "config", "user.email", "test@example.com")
+	runTestGit(t, dir, "config", "commit.gpgsign", "false")
+	writeTestFile(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+	runTestGit(t, dir, "add", "-A")
+	runTestGit(t, dir, "commit", "-q", "-m", "Initial commit")
+	head := strings.TrimSpace(runTestGit(t, dir, "rev-parse", "HEAD"))
+	t.Chdir(dir)
+
+	hashes, err := BlameLines("HEAD", "main.go", [][2]int{{1, 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hashes) != 3 || hashes[0] != head || hashes[2] != head {
+		t.Errorf("Expected all lines to be blamed on %s, got %v", head, hashes)
+	}
+}
@@ -0,0 +1,192 @@
+package git
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// hunkHeaderRegex matches a hunk header like "@@ -12,5 +12,7 @@ func main() {"
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// DiffHunk represents a single hunk of a file diff together with the file header
+// needed to apply it on its own
+type DiffHunk struct {
+	// ID is the 1-based position of the hunk in the original diff
+	ID      int
+	Path    string
+	Header  string
+	Content string
+}
+
+// ParseDiffHunks splits a unified diff into individual hunks. Changes without
+// hunks (binary patches, pure renames, mode changes) are returned as a single
+// hunk with an empty Content.
+func ParseDiffHunks(diff string) []DiffHunk {
+	var hunks []DiffHunk
+	for _, file := range ParseDiff(diff).Files {
+		if len(file.Hunks) == 0 {
+			hunks = append(hunks, DiffHunk{
+				ID:     len(hunks) + 1,
+				Path:   file.Path,
+				Header: file.Header,
+			})
+			continue
+		}
+
+		for _, hunk := range file.Hunks {
+			hunks = append(hunks, DiffHunk{
+				ID:      len(hunks) + 1,
+				Path:    file.Path,
+				Header:  file.Header,
+				Content: hunk.String(),
+			})
+		}
+	}
+	return hunks
+}
+
+// BuildPatch assembles a patch from a subset of hunks, ordering them as they
+// appeared in the original diff and writing each file header only once
+func BuildPatch(hunks []DiffHunk) string {
+	sorted := make([]DiffHunk, len(hunks))
+	copy(sorted, hunks)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	var patch strings.Builder
+	lastHeader := ""
+	for _, hunk := range sorted {
+		if hunk.Header != lastHeader {
+			patch.WriteString(hunk.Header)
+			lastHeader = hunk.Header
+		}
+		patch.WriteString(hunk.Content)
+	}
+
+	return patch.String()
+}
+
+// BuildPatches assembles one patch per group of hunks, for applying the groups one after
+// another. Only the first group touching a renamed or copied file carries the rename or
+// copy; the hunks of later groups change the file at its new path.
+func BuildPatches(groups [][]DiffHunk) []string {
+	moved := make(map[string]bool)
+	patches := make([]string, len(groups))
+	for i, group := range groups {
+		adjusted := make([]DiffHunk, len(group))
+		for j, hunk := range group {
+			if moved[hunk.Header] {
+				adjusted[j] = hunk.againstNewPath()
+			} else {
+				adjusted[j] = hunk
+			}
+		}
+		for _, hunk := range group {
+			moved[hunk.Header] = true
+		}
+		patches[i] = BuildPatch(adjusted)
+	}
+	return patches
+}
+
+// againstNewPath returns a hunk of a renamed or copied file as a change to the file at its
+// new path, once an earlier patch has moved it. Other hunks are returned as is.
+func (h DiffHunk) againstNewPath() DiffHunk {
+	file := FileDiff{Header: h.Header}
+	parseFileDiffHeader(&file)
+	if file.ChangeType != ChangeRenamed && file.ChangeType != ChangeCopied {
+		return h
+	}
+
+	var newSide string
+	for _, line := range strings.Split(h.Header, "\n") {
+		if strings.HasPrefix(line, "+++ ") {
+			newSide = strings.TrimPrefix(line, "+++ ")
+		}
+	}
+	if newSide == "" {
+		return h
+	}
+
+	// Both sides name the new path, quoted like git quoted it
+	oldSide := strings.Replace(newSide, "b/", "a/", 1)
+	h.Header = "diff --git " + oldSide + " " + newSide + "\n--- " + oldSide + "\n+++ " + newSide + "\n"
+	return h
+}
+
+// OldPath returns the path of the file before the change, or an empty string for new files
+func (h DiffHunk) OldPath() string {
+	file := FileDiff{Header: h.Header}
+	parseFileDiffHeader(&file)
+	if file.ChangeType == ChangeAdded {
+		return ""
+	}
+	return file.OldPath
+}
+
+// OldLineRanges returns the 1-based inclusive ranges of lines in the original file that
+// the hunk touches: removed lines, plus the lines surrounding pure insertions
+func (h DiffHunk) OldLineRanges() [][2]int {
+	lines := strings.Split(h.Content, "\n")
+	if len(lines) == 0 {
+		return nil
+	}
+	m := hunkHeaderRegex.FindStringSubmatch(lines[0])
+	if m == nil {
+		return nil
+	}
+
+	oldStart, _ := strconv.Atoi(m[1])
+	oldCount := 1
+	if m[2] != "" {
+		oldCount, _ = strconv.Atoi(m[2])
+	}
+	if oldCount == 0 {
+		return nil
+	}
+	oldEnd := oldStart + oldCount - 1
+
+	touched := make(map[int]bool)
+	oldLine := oldStart
+	runHasRemovals := false
+	for _, line := range lines[1:] {
+		if line == "" {
+			continue
+		}
+		switch line[0] {
+		case '-':
+			touched[oldLine] = true
+			runHasRemovals = true
+			oldLine++
+		case '+':
+			if !runHasRemovals {
+				touched[oldLine-1] = true
+				touched[oldLine] = true
+			}
+		case ' ':
+			runHasRemovals = false
+			oldLine++
+		}
+	}
+
+	var numbers []int
+	for n := range touched {
+		if n >= oldStart && n <= oldEnd {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+
+	var ranges [][2]int
+	for _, n := range numbers {
+		if len(ranges) > 0 && ranges[len(ranges)-1][1] == n-1 {
+			ranges[len(ranges)-1][1] = n
+		} else {
+			ranges = append(ranges, [2]int{n, n})
+		}
+	}
+	return ranges
+}
@@ -0,0 +1,149 @@
+package git
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const multiHunkDiff = `diff --git a/src/main.go b/src/main.go
+index 1234567..abcdef0 100644
+--- a/src/main.go
++++ b/src/main.go
+@@ -1,3 +1,3 @@
+ package main
+-import "fmt"
++import "log"
+ 
+@@ -20,3 +20,4 @@ func main() {
+ 	run()
++	cleanup()
+ }
+diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..1111111
+Binary files /dev/null and b/logo.png differ
+diff --git a/old.txt b/new.txt
+similarity index 100%
+rename from old.txt
+rename to new.txt
+`
+
+func TestParseDiffHunks(t *testing.T) {
+	hunks := ParseDiffHunks(multiHunkDiff)
+
+	if len(hunks) != 4 {
+		t.Fatalf("ParseDiffHunks() returned %d hunks, want 4", len(hunks))
+	}
+
+	expectedPaths := []string{"src/main.go", "src/main.go", "logo.png", "new.txt"}
+	for i, hunk := range hunks {
+		if hunk.ID != i+1 {
+			t.Errorf("hunk %d has ID %d", i, hunk.ID)
+		}
+		if hunk.Path != expectedPaths[i] {
+			t.Errorf("hunk %d has path %q, want %q", i, hunk.Path, expectedPaths[i])
+		}
+	}
+
+	if hunks[0].Header != hunks[1].Header {
+		t.Errorf("hunks of the same file should share the file header")
+	}
+	if hunks[2].Content != "" || hunks[3].Content != "" {
+		t.Errorf("header-only changes should have empty content")
+	}
+}
+
+func TestBuildPatch(t *testing.T) {
+	hunks := ParseDiffHunks(multiHunkDiff)
+
+	// Rebuilding from all hunks in any order gives back the original diff
+	reversed := []DiffHunk{hunks[3], hunks[2], hunks[1], hunks[0]}
+	if patch := BuildPatch(reversed); patch != multiHunkDiff {
+		t.Errorf("BuildPatch() with all hunks = %q, want %q", patch, multiHunkDiff)
+	}
+
+	// A subset keeps the file header once
+	expected := `diff --git a/src/main.go b/src/main.go
+index 1234567..abcdef0 100644
+--- a/src/main.go
++++ b/src/main.go
+@@ -20,3 +20,4 @@ func main() {
+ 	run()
++	cleanup()
+ }
+`
+	if patch := BuildPatch([]DiffHunk{hunks[1]}); patch != expected {
+		t.Errorf("BuildPatch() with one hunk = %q, want %q", patch, expected)
+	}
+}
+
+func TestDiffHunkOldLineRanges(t *testing.T) {
+	hunks := ParseDiffHunks(multiHunkDiff)
+
+	// A replaced line touches only the removed line
+	if ranges := hunks[0].OldLineRanges(); !reflect.DeepEqual(ranges, [][2]int{{2, 2}}) {
+		t.Errorf("OldLineRanges() of replacement = %v, want [[2 2]]", ranges)
+	}
+
+	// A pure insertion touches the lines around it
+	if ranges := hunks[1].OldLineRanges(); !reflect.DeepEqual(ranges, [][2]int{{20, 21}}) {
+		t.Errorf("OldLineRanges() of insertion = %v, want [[20 21]]", ranges)
+	}
+
+	// Header-only changes touch nothing
+	if ranges := hunks[3].OldLineRanges(); ranges != nil {
+		t.Errorf("OldLineRanges() of rename = %v, want nil", ranges)
+	}
+}
+
+func TestDiffHunkOldPath(t *testing.T) {
+	hunks := ParseDiffHunks(multiHunkDiff)
+
+	expected := []string{"src/main.go", "src/main.go", "", "old.txt"}
+	for i, hunk := range hunks {
+		if oldPath := hunk.OldPath(); oldPath != expected[i] {
+			t.Errorf("hunk %d OldPath() = %q, want %q", i+1, oldPath, expected[i])
+		}
+	}
+}
+
+func TestBuildPatchesSplitsRenamedFile(t *testing.T) {
+	dir := initTestRepo(t)
+	var lines []string
+	for i := 1; i <= 30; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	writeTestFile(t, dir, "old.txt", strings.Join(lines, "\n")+"\n")
+	runTestGit(t, dir, "add", "-A")
+	runTestGit(t, dir, "commit", "-q", "-m", "Initial commit")
+
+	// Rename the file and change its start and end, so that there are two hunks
+	runTestGit(t, dir, "mv", "old.txt", "new.txt")
+	lines[1], lines[28] = "changed 2", "changed 29"
+	writeTestFile(t, dir, "new.txt", strings.Join(lines, "\n")+"\n")
+	runTestGit(t, dir, "add", "-A")
+	staged := runTestGit(t, dir, "write-tree")
+	t.Chdir(dir)
+
+	hunks := ParseDiffHunks(GetStagedPatch())
+	if len(hunks) != 2 || hunks[0].OldPath() != "old.txt" {
+		t.Fatalf("Expected two hunks of the renamed file, got %+v", hunks)
+	}
+
+	// Commit the hunks separately, the later one first
+	if err := ResetIndex(); err != nil {
+		t.Fatal(err)
+	}
+	for i, patch := range BuildPatches([][]DiffHunk{{hunks[1]}, {hunks[0]}}) {
+		if err := ApplyPatchToIndex(patch); err != nil {
+			t.Fatalf("Failed to apply patch %d: %v\n%s", i+1, err, patch)
+		}
+		runTestGit(t, dir, "commit", "-q", "-m", fmt.Sprintf("Part %d", i+1))
+	}
+
+	if tree := runTestGit(t, dir, "rev-parse", "HEAD^{tree}"); tree != staged {
+		t.Errorf("Expected the commits to end up with the staged tree %s, got %s", staged, tree)
+	}
+}
@@ -0,0 +1,245 @@
+package git
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/logger"
+)
+
+// IgnoreFileName is the file at the repository root listing paths that are never sent to the LLM
+const IgnoreFileName = ".git-ai-ignore"
+
+// ignoreRule is a single compiled pattern from an ignore file
+type ignoreRule struct {
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// IgnoreMatcher matches paths against patterns in gitignore syntax. Later patterns take
+// precedence, "!" re-includes a path, and a pattern ending in "/" only matches directories.
+// A path is excluded when it or any of its parent directories matches.
+type IgnoreMatcher struct {
+	rules []ignoreRule
+}
+
+// NewIgnoreMatcher compiles gitignore-style patterns. Blank lines and comments are skipped.
+func NewIgnoreMatcher(patterns []string) *IgnoreMatcher {
+	matcher := &IgnoreMatcher{}
+	for _, pattern := range patterns {
+		if rule, ok := compileIgnorePattern(pattern); ok {
+			matcher.rules = append(matcher.rules, rule)
+		}
+	}
+	return matcher
+}
+
+// IsEmpty reports whether the matcher has no patterns
+func (m *IgnoreMatcher) IsEmpty() bool {
+	return m == nil || len(m.rules) == 0
+}
+
+// Match reports whether a repository-relative file path is excluded
+func (m *IgnoreMatcher) Match(path string) bool {
+	if m.IsEmpty() {
+		return false
+	}
+
+	path = strings.TrimPrefix(filepath.ToSlash(path), "/")
+	parts := strings.Split(path, "/")
+
+	excluded := false
+	for _, rule := range m.rules {
+		for i := range parts {
+			isDir := i < len(parts)-1
+			if rule.dirOnly && !isDir {
+				continue
+			}
+			if rule.pattern.MatchString(strings.Join(parts[:i+1], "/")) {
+				excluded = !rule.negate
+				break
+			}
+		}
+	}
+	return excluded
+}
+
+// compileIgnorePattern translates a gitignore pattern into a regular expression matched
+// against a path or one of its parent directories
+func compileIgnorePattern(pattern string) (ignoreRule, bool) {
+	pattern = strings.TrimRight(pattern, " \t\r")
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, `\`) {
+		// "\#" and "\!" match a literal leading character
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	if pattern == "" {
+		return ignoreRule{}, false
+	}
+
+	// Patterns with a slash anywhere but the end are relative to the repository root,
+	// others match at any depth
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			// Leading or inner "**/" matches zero or more directories
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end == -1 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		logger.Warn("Ignoring invalid exclude pattern %q: %v", pattern, err)
+		return ignoreRule{}, false
+	}
+	rule.pattern = re
+	return rule, true
+}
+
+// ReadIgnoreFile reads the patterns from an ignore file. A missing file has no patterns.
+func ReadIgnoreFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+	return patterns, scanner.Err()
+}
+
+// exclusions holds the paths excluded from everything sent to the LLM
+type exclusions struct {
+	matcher *IgnoreMatcher
+	mention bool
+}
+
+// loadExclusions builds the matcher for excluded paths from the exclude_paths setting and
+// the .git-ai-ignore file at the repository root, whose patterns take precedence
+func loadExclusions(cfg config.Config) exclusions {
+	patterns := append([]string{}, cfg.ExcludePaths...)
+
+	out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err == nil {
+		filePatterns, err := ReadIgnoreFile(filepath.Join(strings.TrimSpace(string(out)), IgnoreFileName))
+		if err != nil {
+			logger.Warn("Could not read %s: %v", IgnoreFileName, err)
+		}
+		patterns = append(patterns, filePatterns...)
+	}
+
+	return exclusions{matcher: NewIgnoreMatcher(patterns), mention: cfg.MentionExcludedPaths}
+}
+
+// matchFile reports whether a changed file is excluded. Renamed and copied files are
+// excluded when either their old or their new path is, so that moving a file out of an
+// excluded directory doesn't send its contents.
+func (e exclusions) matchFile(oldPath, newPath string) bool {
+	return (oldPath != "" && e.matcher.Match(oldPath)) || (newPath != "" && e.matcher.Match(newPath))
+}
+
+// IsExcludedPath reports whether a path is excluded from everything sent to the LLM by
+// .git-ai-ignore or the exclude_paths setting
+func IsExcludedPath(cfg config.Config, path string) bool {
+	return loadExclusions(cfg).matcher.Match(path)
+}
+
+// FilterExcludedFiles removes excluded paths from a newline-separated file list. With
+// mention_excluded_paths set they are kept and marked instead, so that the LLM still knows
+// the files changed without seeing their contents.
+func FilterExcludedFiles(cfg config.Config, files string) string {
+	excluded := loadExclusions(cfg)
+	if excluded.matcher.IsEmpty() {
+		return files
+	}
+
+	var result strings.Builder
+	for _, file := range strings.Split(files, "\n") {
+		if strings.TrimSpace(file) == "" {
+			continue
+		}
+		if excluded.matcher.Match(file) {
+			if excluded.mention {
+				result.WriteString(file + " (contents excluded)\n")
+			}
+			continue
+		}
+		result.WriteString(file + "\n")
+	}
+	return result.String()
+}
+
+// MaskExcludedHunks returns copies of the hunks with the contents of excluded files
+// replaced by a note. The originals are left untouched so they can still be applied.
+func MaskExcludedHunks(cfg config.Config, hunks []DiffHunk) []DiffHunk {
+	excluded := loadExclusions(cfg)
+	masked := make([]DiffHunk, len(hunks))
+	for i, hunk := range hunks {
+		if excluded.matchFile(hunk.OldPath(), hunk.Path) {
+			hunk.Header = "diff --git a/" + hunk.Path + " b/" + hunk.Path + "\n"
+			hunk.Content = "(contents excluded)\n"
+		}
+		masked[i] = hunk
+	}
+	return masked
+}
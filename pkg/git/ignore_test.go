@@ -0,0 +1,164 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/recrsn/git-ai/pkg/config"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	matcher := NewIgnoreMatcher([]string{
+		"# comment",
+		"",
+		"secrets/",
+		"/customer-data",
+		"*.pem",
+		"src/**/algorithm_*.go",
+		"build/**",
+		"docs/*.md",
+		"!docs/README.md",
+		"report-?.csv",
+		"config.[ch]",
+	})
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		// Directory patterns match anything below the directory, at any depth
+		{"secrets/prod.env", true},
+		{"deploy/secrets/key.txt", true},
+		{"secrets", false},
+		// Anchored patterns only match from the repository root
+		{"customer-data/export.csv", true},
+		{"customer-data", true},
+		{"app/customer-data/export.csv", false},
+		// Unanchored globs match the file name at any depth
+		{"cert.pem", true},
+		{"tls/server.pem", true},
+		{"server.pem.txt", false},
+		// "**" matches any number of directories
+		{"src/algorithm_rank.go", true},
+		{"src/core/scoring/algorithm_rank.go", true},
+		{"lib/algorithm_rank.go", false},
+		{"build/out/app.bin", true},
+		// "*" does not cross directories, and later negations re-include files
+		{"docs/guide.md", true},
+		{"docs/api/guide.md", false},
+		{"docs/README.md", false},
+		// "?" and character classes
+		{"report-1.csv", true},
+		{"report-10.csv", false},
+		{"config.c", true},
+		{"config.go", false},
+		{"main.go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if result := matcher.Match(tt.path); result != tt.expected {
+				t.Errorf("Match(%q) = %v, want %v", tt.path, result, tt.expected)
+			}
+		})
+	}
+
+	if !NewIgnoreMatcher([]string{"# only a comment", "  "}).IsEmpty() {
+		t.Errorf("Expected a matcher without patterns to be empty")
+	}
+}
+
+func TestReadIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+
+	patterns, err := ReadIgnoreFile(filepath.Join(dir, IgnoreFileName))
+	if err != nil || patterns != nil {
+		t.Errorf("Expected a missing ignore file to have no patterns, got %v, %v", patterns, err)
+	}
+
+	path := filepath.Join(dir, IgnoreFileName)
+	if err := os.WriteFile(path, []byte("secrets/\n*.key\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	patterns, err = ReadIgnoreFile(path)
+	if err != nil {
+		t.Fatalf("ReadIgnoreFile() error = %v", err)
+	}
+	if len(patterns) != 2 || patterns[0] != "secrets/" || patterns[1] != "*.key" {
+		t.Errorf("ReadIgnoreFile() = %q", patterns)
+	}
+}
+
+// movedOutOfSecretsDiff renames a file out of an excluded directory and edits it
+const movedOutOfSecretsDiff = `diff --git a/secrets/key.txt b/other/key.txt
+similarity index 80%
+rename from secrets/key.txt
+rename to other/key.txt
+index 1234567..abcdef0 100644
+--- a/secrets/key.txt
++++ b/other/key.txt
+@@ -1,2 +1,2 @@
+ token=hunter2
+-user=admin
++user=root
+diff --git a/main.go b/main.go
+index 1234567..abcdef0 100644
+--- a/main.go
++++ b/main.go
+@@ -1 +1 @@
+-package old
++package main
+`
+
+func TestFilterGeneratedDiffExcludesRenamedFiles(t *testing.T) {
+	cfg := config.Config{ExcludePaths: []string{"secrets/"}}
+
+	result := FilterGeneratedDiff(cfg, movedOutOfSecretsDiff)
+	if strings.Contains(result, "hunter2") || strings.Contains(result, "key.txt") {
+		t.Errorf("Expected a file moved out of an excluded directory to be left out:\n%s", result)
+	}
+	if !strings.Contains(result, "+package main") {
+		t.Errorf("Expected other files to be kept:\n%s", result)
+	}
+
+	cfg.MentionExcludedPaths = true
+	result = FilterGeneratedDiff(cfg, movedOutOfSecretsDiff)
+	if !strings.Contains(result, "excluded") || strings.Contains(result, "hunter2") {
+		t.Errorf("Expected the excluded file to be mentioned without its contents:\n%s", result)
+	}
+
+	if result := FilterGeneratedDiff(config.Config{}, movedOutOfSecretsDiff); !strings.Contains(result, "hunter2") {
+		t.Errorf("Expected nothing to be excluded without patterns:\n%s", result)
+	}
+}
+
+func TestMaskExcludedHunks(t *testing.T) {
+	hunks := ParseDiffHunks(movedOutOfSecretsDiff)
+	masked := MaskExcludedHunks(config.Config{ExcludePaths: []string{"secrets/"}}, hunks)
+
+	if masked[0].Content != "(contents excluded)\n" || strings.Contains(masked[0].Header, "secrets/") {
+		t.Errorf("Expected the hunk of the renamed file to be masked, got %+v", masked[0])
+	}
+	if masked[1].Content != hunks[1].Content {
+		t.Errorf("Expected other hunks to be kept")
+	}
+	if !strings.Contains(hunks[0].Content, "hunter2") {
+		t.Errorf("Expected the original hunks to be left untouched")
+	}
+}
+
+func TestFilterExcludedFiles(t *testing.T) {
+	files := "main.go\nsecrets/prod.env\n"
+	cfg := config.Config{ExcludePaths: []string{"secrets/"}}
+
+	if result := FilterExcludedFiles(cfg, files); result != "main.go\n" {
+		t.Errorf("FilterExcludedFiles() = %q", result)
+	}
+
+	cfg.MentionExcludedPaths = true
+	if result := FilterExcludedFiles(cfg, files); result != "main.go\nsecrets/prod.env (contents excluded)\n" {
+		t.Errorf("FilterExcludedFiles() with mention_excluded_paths = %q", result)
+	}
+}
@@ -0,0 +1,74 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/recrsn/git-ai/pkg/logger"
+)
+
+// LogEntry is a commit as shown in a work log
+type LogEntry struct {
+	Hash    string
+	Date    string
+	Message string
+}
+
+// Subject returns the first line of the commit message
+func (e LogEntry) Subject() string {
+	subject, _, _ := strings.Cut(e.Message, "\n")
+	return subject
+}
+
+// GetRepoUserEmail returns the user.email configured for the repository at path
+func GetRepoUserEmail(repo string) (string, error) {
+	cmd := exec.Command("git", "-C", repo, "config", "--get", "user.email")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("user.email is not configured for %s", repo)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// GetRepoWorkLog returns the non-merge commits on any local branch of the repository at path,
+// oldest first. since and until accept any date git understands (e.g. "yesterday"); empty values
+// and an empty author are not filtered on.
+func GetRepoWorkLog(repo, since, until, author string) ([]LogEntry, error) {
+	args := []string{"-C", repo, "log", "--branches", "--no-merges", "--reverse", "--date=short", "--format=%h%x1f%ad%x1f%B%x00"}
+	if since != "" {
+		args = append(args, "--since="+since)
+	}
+	if until != "" {
+		args = append(args, "--until="+until)
+	}
+	if author != "" {
+		args = append(args, "--author="+author)
+	}
+
+	cmd := exec.Command("git", args...)
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error reading log of %s: %v: %s", repo, err, stderr.String())
+		return nil, fmt.Errorf("error reading log of %s: %v: %s", repo, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var entries []LogEntry
+	for _, record := range strings.Split(out.String(), "\x00") {
+		fields := strings.SplitN(strings.TrimSpace(record), "\x1f", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		entries = append(entries, LogEntry{
+			Hash:    fields[0],
+			Date:    fields[1],
+			Message: strings.TrimSpace(fields[2]),
+		})
+	}
+	return entries, nil
+}
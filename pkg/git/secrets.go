@@ -0,0 +1,280 @@
+package git
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/recrsn/git-ai/pkg/config"
+	"github.com/recrsn/git-ai/pkg/logger"
+)
+
+// SecretFinding describes a possible secret found in content sent to the LLM
+type SecretFinding struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Kind string `json:"kind"`
+}
+
+// String formats the finding as "path:line (kind)"
+func (f SecretFinding) String() string {
+	if f.Line > 0 {
+		return fmt.Sprintf("%s:%d (%s)", f.Path, f.Line, f.Kind)
+	}
+	return fmt.Sprintf("%s (%s)", f.Path, f.Kind)
+}
+
+// secretDetector finds one kind of secret. If the pattern has a capture group, only the
+// group is masked; otherwise the whole match is. An optional check filters out matches.
+type secretDetector struct {
+	kind    string
+	pattern *regexp.Regexp
+	check   func(match string) bool
+}
+
+var (
+	privateKeyBeginRegex = regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----`)
+	privateKeyEndRegex   = regexp.MustCompile(`-----END [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----`)
+
+	builtinSecretDetectors = []secretDetector{
+		{kind: "AWS access key", pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`)},
+		{kind: "GitHub token", pattern: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+		{kind: "Slack token", pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`)},
+		{kind: "JWT", pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+		// KEY=value assignments as found in .env files and shell scripts
+		{kind: "credential assignment", pattern: regexp.MustCompile(`\b[A-Z0-9_]*(?:PASSWORD|PASSWD|SECRET|API_?KEY|TOKEN|PRIVATE_KEY|CREDENTIALS?)[A-Z0-9_]*\s*=\s*["']?([^\s"'$]{8,})`)},
+		// Quoted values assigned to credential-like names in code and config files
+		{kind: "credential assignment", pattern: regexp.MustCompile(`(?i)(?:password|passwd|secret|api[_-]?key|access[_-]?key|auth[_-]?token|token)["']?\s*[:=]\s*["']([^"'\s]{8,})["']`)},
+		{kind: "high-entropy string", pattern: regexp.MustCompile(`[A-Za-z0-9+/_-]{32,}={0,2}`), check: isHighEntropySecret},
+	}
+
+	redactionPlaceholderRegex = regexp.MustCompile(`\[REDACTED [a-zA-Z -]+ #\d+\]`)
+)
+
+// SecretScanner finds and masks secrets in content before it is sent to the LLM.
+// Every distinct secret is replaced by a numbered placeholder so that it can be restored
+// in responses that quote it.
+type SecretScanner struct {
+	detectors    []secretDetector
+	placeholders map[string]string
+	originals    map[string]string
+}
+
+// NewSecretScanner creates a scanner with the built-in detectors and the secret_patterns
+// from the configuration. Invalid patterns are skipped with a warning.
+func NewSecretScanner(cfg config.Config) *SecretScanner {
+	detectors := append([]secretDetector{}, builtinSecretDetectors...)
+	for _, pattern := range cfg.SecretPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			logger.Warn("Ignoring invalid secret pattern %q: %v", pattern, err)
+			continue
+		}
+		detectors = append(detectors, secretDetector{kind: "custom pattern", pattern: re})
+	}
+
+	return &SecretScanner{
+		detectors:    detectors,
+		placeholders: make(map[string]string),
+		originals:    make(map[string]string),
+	}
+}
+
+// RedactSecrets masks secrets in a diff using the built-in detectors and configured patterns
+func RedactSecrets(cfg config.Config, diff string) (string, []SecretFinding) {
+	return NewSecretScanner(cfg).RedactDiff(diff)
+}
+
+// RedactDiff masks secrets in the changed and context lines of a unified diff and reports
+// where they were found. Line numbers refer to the new file, or the old one for removed lines.
+func (s *SecretScanner) RedactDiff(diff string) (string, []SecretFinding) {
+	if diff == "" {
+		return diff, nil
+	}
+
+	var findings []SecretFinding
+	parsed := ParseDiff(diff)
+	for _, file := range parsed.Files {
+		inPrivateKey := false
+		for _, hunk := range file.Hunks {
+			for i, line := range hunk.Lines {
+				lineNumber := line.NewNumber
+				switch line.Kind {
+				case LineRemoved:
+					lineNumber = line.OldNumber
+				case LineNoNewline:
+					continue
+				}
+
+				var lineFindings []SecretFinding
+				hunk.Lines[i].Text, lineFindings = s.redactLine(line.Text, file.Path, lineNumber, &inPrivateKey)
+				findings = append(findings, lineFindings...)
+			}
+		}
+	}
+
+	// Keep a missing trailing newline missing, so unchanged diffs come back as they were
+	redacted := parsed.String()
+	if !strings.HasSuffix(diff, "\n") {
+		redacted = strings.TrimSuffix(redacted, "\n")
+	}
+	return redacted, findings
+}
+
+// RedactHunks returns copies of the hunks with secrets masked in their content. The
+// originals are left untouched so they can still be applied as patches.
+func (s *SecretScanner) RedactHunks(hunks []DiffHunk) ([]DiffHunk, []SecretFinding) {
+	var findings []SecretFinding
+	redacted := make([]DiffHunk, len(hunks))
+	for i, hunk := range hunks {
+		// Redact the hunk together with its file header, which stays the same
+		patch, hunkFindings := s.RedactDiff(hunk.Header + hunk.Content)
+		hunk.Content = patch[len(hunk.Header):]
+		redacted[i] = hunk
+		findings = append(findings, hunkFindings...)
+	}
+	return redacted, findings
+}
+
+// RedactText masks secrets in a piece of a file, e.g. one side of a merge conflict.
+// firstLine is the line number of the first line of text in path, or 0 if unknown.
+func (s *SecretScanner) RedactText(path string, firstLine int, text string) (string, []SecretFinding) {
+	var findings []SecretFinding
+	inPrivateKey := false
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lineNumber := 0
+		if firstLine > 0 {
+			lineNumber = firstLine + i
+		}
+
+		var lineFindings []SecretFinding
+		lines[i], lineFindings = s.redactLine(line, path, lineNumber, &inPrivateKey)
+		findings = append(findings, lineFindings...)
+	}
+	return strings.Join(lines, "\n"), findings
+}
+
+// Restore replaces the placeholders in text with the secrets they stand for
+func (s *SecretScanner) Restore(text string) string {
+	return redactionPlaceholderRegex.ReplaceAllStringFunc(text, func(placeholder string) string {
+		if original, ok := s.originals[placeholder]; ok {
+			return original
+		}
+		return placeholder
+	})
+}
+
+// redactLine masks the secrets in a single line. inPrivateKey tracks whether the line is
+// inside a private key block, whose lines are masked while the BEGIN/END markers are kept.
+func (s *SecretScanner) redactLine(line, path string, lineNumber int, inPrivateKey *bool) (string, []SecretFinding) {
+	if *inPrivateKey {
+		if privateKeyEndRegex.MatchString(line) {
+			*inPrivateKey = false
+			return line, nil
+		}
+		if strings.TrimSpace(line) == "" {
+			return line, nil
+		}
+		return s.mask("private key", line), nil
+	}
+	if privateKeyBeginRegex.MatchString(line) {
+		findings := []SecretFinding{{Path: path, Line: lineNumber, Kind: "private key"}}
+		if privateKeyEndRegex.MatchString(line) {
+			// The whole key is on one line, e.g. in a JSON credentials file
+			return s.mask("private key", line), findings
+		}
+		*inPrivateKey = true
+		return line, findings
+	}
+
+	var findings []SecretFinding
+	for _, detector := range s.detectors {
+		found := false
+		line = replaceAllSubmatchFunc(detector.pattern, line, func(secret string) string {
+			// Don't mask placeholders of other detectors again
+			if redactionPlaceholderRegex.MatchString(secret) || (detector.check != nil && !detector.check(secret)) {
+				return secret
+			}
+			found = true
+			return s.mask(detector.kind, secret)
+		})
+		if found {
+			findings = append(findings, SecretFinding{Path: path, Line: lineNumber, Kind: detector.kind})
+		}
+	}
+	return line, findings
+}
+
+// mask returns the placeholder for a secret, reusing it if the secret was seen before
+func (s *SecretScanner) mask(kind, secret string) string {
+	if placeholder, ok := s.placeholders[secret]; ok {
+		return placeholder
+	}
+	placeholder := fmt.Sprintf("[REDACTED %s #%d]", kind, len(s.placeholders)+1)
+	s.placeholders[secret] = placeholder
+	s.originals[placeholder] = secret
+	return placeholder
+}
+
+// replaceAllSubmatchFunc replaces every match of re in text with the result of repl. If the
+// pattern has a capture group, only the text of the first group is replaced.
+func replaceAllSubmatchFunc(re *regexp.Regexp, text string, repl func(string) string) string {
+	matches := re.FindAllStringSubmatchIndex(text, -1)
+	if matches == nil {
+		return text
+	}
+
+	var result strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if len(m) >= 4 && m[2] >= 0 {
+			start, end = m[2], m[3]
+		}
+		result.WriteString(text[last:start])
+		result.WriteString(repl(text[start:end]))
+		last = end
+	}
+	result.WriteString(text[last:])
+	return result.String()
+}
+
+// isHighEntropySecret reports whether a token looks like a random key rather than an
+// identifier, path or hex hash: it mixes upper case, lower case and digits and has a
+// high Shannon entropy.
+func isHighEntropySecret(token string) bool {
+	var hasUpper, hasLower, hasDigit bool
+	for _, r := range token {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			hasUpper = true
+		case r >= 'a' && r <= 'z':
+			hasLower = true
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		}
+	}
+	if !hasUpper || !hasLower || !hasDigit {
+		return false
+	}
+	return shannonEntropy(token) >= 4.3
+}
+
+// shannonEntropy returns the Shannon entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
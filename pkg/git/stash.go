@@ -0,0 +1,141 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/recrsn/git-ai/pkg/logger"
+)
+
+// StashEntry represents a single entry of the stash list
+type StashEntry struct {
+	// Ref is the reflog selector, e.g. stash@{0}
+	Ref     string
+	Hash    string
+	Message string
+}
+
+// IsAnonymous reports whether the stash was created without a message, in which case
+// git labels it "WIP on <branch>: <hash> <subject>"
+func (s StashEntry) IsAnonymous() bool {
+	return strings.HasPrefix(s.Message, "WIP on ")
+}
+
+// Branch returns the branch the stash was created on, as recorded in its message
+func (s StashEntry) Branch() string {
+	message := strings.TrimPrefix(strings.TrimPrefix(s.Message, "WIP on "), "On ")
+	branch, _, found := strings.Cut(message, ": ")
+	if !found {
+		return ""
+	}
+	return branch
+}
+
+// ListStashes returns the stash entries, newest first
+func ListStashes() ([]StashEntry, error) {
+	cmd := exec.Command("git", "stash", "list", "--format=%gd%x00%H%x00%gs")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error listing stashes: %v", err)
+		return nil, fmt.Errorf("error listing stashes: %v", err)
+	}
+
+	var entries []StashEntry
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		fields := strings.SplitN(line, "\x00", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		entries = append(entries, StashEntry{
+			Ref:     fields[0],
+			Hash:    fields[1],
+			Message: fields[2],
+		})
+	}
+	return entries, nil
+}
+
+// GetStashDiff returns the diff of the changes saved in a stash entry
+func GetStashDiff(ref string) string {
+	cmd := exec.Command("git", "stash", "show", "--patch", ref)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error getting diff of %s: %v", ref, err)
+		return ""
+	}
+	return out.String()
+}
+
+// StashPush stashes the local changes with the given message
+func StashPush(message string, includeUntracked bool) error {
+	args := []string{"stash", "push", "-m", message}
+	if includeUntracked {
+		args = append(args, "--include-untracked")
+	}
+	cmd := exec.Command("git", args...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error stashing changes: %v: %s", err, stderr.String())
+		return fmt.Errorf("error stashing changes: %v: %s", err, stderr.String())
+	}
+	return nil
+}
+
+// RewriteStashes replaces the stash list with the given entries (newest first),
+// which allows changing their messages while keeping their order. If an entry can't be
+// stored, the original stash list is restored.
+func RewriteStashes(entries []StashEntry) error {
+	original, err := ListStashes()
+	if err != nil {
+		return err
+	}
+
+	var hashes []string
+	for _, entry := range original {
+		hashes = append(hashes, entry.Hash)
+	}
+	logger.Info("Rewriting stash list, entries: %s", strings.Join(hashes, " "))
+
+	err = replaceStashes(entries)
+	if err == nil {
+		return nil
+	}
+
+	if restoreErr := replaceStashes(original); restoreErr != nil {
+		return fmt.Errorf("%v; restoring the stash list failed: %v (stash commits: %s)", err, restoreErr, strings.Join(hashes, " "))
+	}
+	return fmt.Errorf("%v; the stash list was left unchanged", err)
+}
+
+// replaceStashes clears the stash list and stores the given entries (newest first)
+func replaceStashes(entries []StashEntry) error {
+	cmd := exec.Command("git", "update-ref", "-d", "refs/stash")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		logger.Error("Error clearing stash list: %v: %s", err, stderr.String())
+		return fmt.Errorf("error clearing stash list: %v: %s", err, stderr.String())
+	}
+
+	// Store the oldest entry first so the newest ends up as stash@{0}
+	for i := len(entries) - 1; i >= 0; i-- {
+		cmd := exec.Command("git", "stash", "store", "-m", entries[i].Message, entries[i].Hash)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			logger.Error("Error storing stash %s: %v: %s", entries[i].Hash, err, stderr.String())
+			return fmt.Errorf("error storing stash %s: %v: %s", entries[i].Hash, err, strings.TrimSpace(stderr.String()))
+		}
+	}
+	return nil
+}
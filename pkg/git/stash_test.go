@@ -0,0 +1,63 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+// stashTestRepo creates a repository with two stashes and makes it the current directory
+func stashTestRepo(t *testing.T) []StashEntry {
+	t.Helper()
+	dir := initTestRepo(t)
+	writeTestFile(t, dir, "main.go", "package main\n")
+	runTestGit(t, dir, "add", "-A")
+	runTestGit(t, dir, "commit", "-q", "-m", "Initial commit")
+	for _, content := range []string{"package first\n", "package second\n"} {
+		writeTestFile(t, dir, "main.go", content)
+		runTestGit(t, dir, "stash", "push", "-q")
+	}
+	t.Chdir(dir)
+
+	entries, err := ListStashes()
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("Expected two stashes, got %v, %v", entries, err)
+	}
+	return entries
+}
+
+func TestRewriteStashes(t *testing.T) {
+	entries := stashTestRepo(t)
+
+	relabeled := append([]StashEntry{}, entries...)
+	relabeled[1].Message = "On master: Rename package to first"
+	if err := RewriteStashes(relabeled); err != nil {
+		t.Fatalf("RewriteStashes() error = %v", err)
+	}
+
+	result, err := ListStashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || result[0] != entries[0] || result[1].Hash != entries[1].Hash || result[1].Message != relabeled[1].Message {
+		t.Errorf("Expected the second stash to be relabeled in place, got %+v", result)
+	}
+}
+
+func TestRewriteStashesRestoresOnFailure(t *testing.T) {
+	entries := stashTestRepo(t)
+
+	// A missing commit can't be stored, after the older entry already was
+	broken := append([]StashEntry{}, entries...)
+	broken[0].Hash = "0123456789abcdef0123456789abcdef01234567"
+	if err := RewriteStashes(broken); err == nil {
+		t.Fatalf("Expected storing a missing commit to fail")
+	}
+
+	result, err := ListStashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result, entries) {
+		t.Errorf("Expected the stash list to be restored, got %+v, want %+v", result, entries)
+	}
+}
@@ -0,0 +1,52 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/recrsn/git-ai/pkg/logger"
+)
+
+// GetLatestVersionTag returns the highest semver release tag reachable from HEAD.
+// Prerelease tags are ignored. It returns an empty name if there is no such tag.
+func GetLatestVersionTag() (string, Version, error) {
+	cmd := exec.Command("git", "tag", "--list", "--merged", "HEAD", "--sort=-v:refname")
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error listing tags: %v: %s", err, stderr.String())
+		return "", Version{}, fmt.Errorf("error listing tags: %v: %s", err, stderr.String())
+	}
+
+	for _, name := range strings.Split(out.String(), "\n") {
+		name = strings.TrimSpace(name)
+		if version, ok := ParseVersion(name); ok && version.Prerelease == "" {
+			return name, version, nil
+		}
+	}
+	return "", Version{}, nil
+}
+
+// TagExists checks whether a tag with the given name exists
+func TagExists(name string) bool {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", "refs/tags/"+name)
+	return cmd.Run() == nil
+}
+
+// CreateAnnotatedTag creates an annotated tag at HEAD with the given message
+func CreateAnnotatedTag(name, message string) error {
+	cmd := exec.Command("git", "tag", "--annotate", "--cleanup=verbatim", "--file=-", name)
+	cmd.Stdin = strings.NewReader(message)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		logger.Error("Error creating tag: %v: %s", err, stderr.String())
+		return fmt.Errorf("error creating tag: %v: %s", err, stderr.String())
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	semverRegex = regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+	// conventionalSubjectRegex matches "type(scope)!: description" subjects
+	conventionalSubjectRegex = regexp.MustCompile(`^([a-zA-Z]+)(\([^)]*\))?(!)?: .+`)
+
+	breakingChangeTrailerRegex = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+)
+
+// BumpLevel is the kind of semantic version increment a set of changes requires
+type BumpLevel int
+
+const (
+	// BumpNone means no release is needed
+	BumpNone BumpLevel = iota
+	// BumpPatch is for backwards compatible bug fixes
+	BumpPatch
+	// BumpMinor is for backwards compatible features
+	BumpMinor
+	// BumpMajor is for breaking changes
+	BumpMajor
+)
+
+// String returns the name of the bump level
+func (b BumpLevel) String() string {
+	switch b {
+	case BumpMajor:
+		return "major"
+	case BumpMinor:
+		return "minor"
+	case BumpPatch:
+		return "patch"
+	default:
+		return "none"
+	}
+}
+
+// ParseBumpLevel parses "major", "minor" or "patch"
+func ParseBumpLevel(s string) (BumpLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "major":
+		return BumpMajor, nil
+	case "minor":
+		return BumpMinor, nil
+	case "patch":
+		return BumpPatch, nil
+	default:
+		return BumpNone, fmt.Errorf("invalid bump level %q, expected major, minor or patch", s)
+	}
+}
+
+// Version is a semantic version, optionally prefixed with "v"
+type Version struct {
+	Prefix     string
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+}
+
+// ParseVersion parses a semantic version such as "v1.2.3" or "1.2.3-rc.1"
+func ParseVersion(s string) (Version, bool) {
+	m := semverRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Version{}, false
+	}
+
+	major, _ := strconv.Atoi(m[2])
+	minor, _ := strconv.Atoi(m[3])
+	patch, _ := strconv.Atoi(m[4])
+	return Version{
+		Prefix:     m[1],
+		Major:      major,
+		Minor:      minor,
+		Patch:      patch,
+		Prerelease: m[5],
+	}, true
+}
+
+// String formats the version including its prefix
+func (v Version) String() string {
+	s := fmt.Sprintf("%s%d.%d.%d", v.Prefix, v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// Bump returns the next version for the given bump level. Breaking changes bump the
+// major version, also before 1.0.0.
+func (v Version) Bump(level BumpLevel) Version {
+	next := Version{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+
+	// A prerelease is promoted to its release version
+	if v.Prerelease != "" {
+		return next
+	}
+
+	switch level {
+	case BumpMajor:
+		next.Major++
+		next.Minor = 0
+		next.Patch = 0
+	case BumpMinor:
+		next.Minor++
+		next.Patch = 0
+	case BumpPatch:
+		next.Patch++
+	}
+	return next
+}
+
+// ClassifyCommit determines the bump level a commit message calls for following the
+// conventional commits specification. The second return value is false if the message
+// is not a conventional commit.
+func ClassifyCommit(message string) (BumpLevel, bool) {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	m := conventionalSubjectRegex.FindStringSubmatch(subject)
+	if m == nil {
+		return BumpPatch, false
+	}
+
+	if m[3] == "!" || breakingChangeTrailerRegex.MatchString(message) {
+		return BumpMajor, true
+	}
+	if strings.ToLower(m[1]) == "feat" {
+		return BumpMinor, true
+	}
+	return BumpPatch, true
+}
@@ -0,0 +1,76 @@
+package git
+
+import (
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		valid    bool
+	}{
+		{"v1.2.3", "v1.2.3", true},
+		{"1.2.3", "1.2.3", true},
+		{"v2.0.0-rc.1", "v2.0.0-rc.1", true},
+		{"v1.2.3+build.5", "v1.2.3", true},
+		{"v1.2", "", false},
+		{"release-1.2.3", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			version, ok := ParseVersion(tt.input)
+			if ok != tt.valid {
+				t.Fatalf("ParseVersion(%q) valid = %v, want %v", tt.input, ok, tt.valid)
+			}
+			if ok && version.String() != tt.expected {
+				t.Errorf("ParseVersion(%q) = %q, want %q", tt.input, version.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestVersionBump(t *testing.T) {
+	tests := []struct {
+		version  string
+		level    BumpLevel
+		expected string
+	}{
+		{"v1.2.3", BumpPatch, "v1.2.4"},
+		{"v1.2.3", BumpMinor, "v1.3.0"},
+		{"v1.2.3", BumpMajor, "v2.0.0"},
+		{"0.4.1", BumpMajor, "1.0.0"},
+		{"0.4.1", BumpMinor, "0.5.0"},
+		{"v2.0.0-rc.2", BumpMinor, "v2.0.0"},
+	}
+
+	for _, tt := range tests {
+		version, _ := ParseVersion(tt.version)
+		if next := version.Bump(tt.level).String(); next != tt.expected {
+			t.Errorf("%s bumped by %s = %s, want %s", tt.version, tt.level, next, tt.expected)
+		}
+	}
+}
+
+func TestClassifyCommit(t *testing.T) {
+	tests := []struct {
+		message      string
+		level        BumpLevel
+		conventional bool
+	}{
+		{"fix(auth): handle expired tokens", BumpPatch, true},
+		{"feat: add export endpoint", BumpMinor, true},
+		{"feat(api)!: drop v1 routes", BumpMajor, true},
+		{"refactor: simplify config loading\n\nBREAKING CHANGE: config files moved", BumpMajor, true},
+		{"chore: bump dependencies", BumpPatch, true},
+		{"Add export endpoint", BumpPatch, false},
+	}
+
+	for _, tt := range tests {
+		level, conventional := ClassifyCommit(tt.message)
+		if level != tt.level || conventional != tt.conventional {
+			t.Errorf("ClassifyCommit(%q) = %s, %v, want %s, %v", tt.message, level, conventional, tt.level, tt.conventional)
+		}
+	}
+}
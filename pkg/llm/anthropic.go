@@ -44,9 +44,16 @@ type AnthropicResponse struct {
 	Role    string             `json:"role"`
 	Content []AnthropicContent `json:"content"`
 	Model   string             `json:"model"`
+	Usage   *AnthropicUsage    `json:"usage,omitempty"`
 	Error   *AnthropicError    `json:"error,omitempty"`
 }
 
+// AnthropicUsage represents the token usage reported by the API
+type AnthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
 // AnthropicError represents an error from Anthropic's API
 type AnthropicError struct {
 	Type    string `json:"type"`
@@ -137,6 +144,10 @@ func (p *AnthropicProvider) ChatCompletion(model string, messages []Message) (st
 		return "", fmt.Errorf("API error: %s", respData.Error.Message)
 	}
 
+	if respData.Usage != nil {
+		RecordUsage(respData.Usage.InputTokens, respData.Usage.OutputTokens)
+	}
+
 	if len(respData.Content) == 0 {
 		return "", fmt.Errorf("no content returned")
 	}
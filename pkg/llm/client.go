@@ -2,6 +2,10 @@ package llm
 
 import (
 	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/recrsn/git-ai/pkg/logger"
 )
 
 // Client represents a unified client that delegates to provider-specific implementations
@@ -47,5 +51,73 @@ func NewClientWithProvider(providerType, endpoint, apiKey string) (*Client, erro
 
 // ChatCompletion sends a chat completion request via the configured provider
 func (c *Client) ChatCompletion(model string, messages []Message) (string, error) {
+	waitForRateLimit(messages, 1)
 	return c.provider.ChatCompletion(model, messages)
 }
+
+// ChatCompletions requests n alternative completions for the same conversation. Providers
+// that support it return them from a single request; missing completions are requested
+// in parallel. Failed requests are only reported if no completion succeeded.
+func (c *Client) ChatCompletions(model string, messages []Message, n int) ([]string, error) {
+	var responses []string
+	if multi, ok := c.provider.(MultiCompletionProvider); ok && n > 1 {
+		var err error
+		waitForRateLimit(messages, n)
+		responses, err = multi.ChatCompletions(model, messages, n)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	missing := n - len(responses)
+	if missing <= 0 {
+		return responses, nil
+	}
+
+	results := make([]string, missing)
+	errs := make([]error, missing)
+	var wg sync.WaitGroup
+	for i := 0; i < missing; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			waitForRateLimit(messages, 1)
+			results[i], errs[i] = c.provider.ChatCompletion(model, messages)
+		}(i)
+	}
+	wg.Wait()
+
+	var firstErr error
+	for i, err := range errs {
+		if err != nil {
+			logger.Warn("Failed to get alternative completion: %v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		responses = append(responses, results[i])
+	}
+
+	if len(responses) == 0 {
+		return nil, firstErr
+	}
+	return responses, nil
+}
+
+// Deduplicate trims the responses and removes duplicates, ignoring case and whitespace
+// differences. The order of first occurrence is kept.
+func Deduplicate(responses []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, response := range responses {
+		response = strings.TrimSpace(response)
+		key := strings.ToLower(strings.Join(strings.Fields(response), " "))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, response)
+	}
+	return result
+}
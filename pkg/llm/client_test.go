@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 )
 
@@ -362,3 +363,98 @@ func (e *errorRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 		Body:       io.NopCloser(errorReader{}),
 	}, nil
 }
+
+func TestOpenAIChatCompletionsUsesNParameter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OpenAIRequest
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("Failed to unmarshal request: %v", err)
+		}
+		if req.N != 3 {
+			t.Errorf("Expected n=3, got %d", req.N)
+		}
+
+		response := OpenAIResponse{}
+		for _, content := range []string{"Fix login", "Fix login redirect", "Repair login"} {
+			response.Choices = append(response.Choices, OpenAIChoice{
+				Message: OpenAIMessage{Role: "assistant", Content: content},
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(server.URL, "test-api-key")
+	responses, err := client.ChatCompletions("gpt-4o", []Message{{Role: "user", Content: "Hello"}}, 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(responses) != 3 || responses[1] != "Fix login redirect" {
+		t.Errorf("Unexpected responses: %v", responses)
+	}
+}
+
+func TestChatCompletionsFallsBackToParallelRequests(t *testing.T) {
+	// A server that ignores n and always returns a single choice
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		response := OpenAIResponse{
+			Choices: []OpenAIChoice{{Message: OpenAIMessage{Role: "assistant", Content: "Fix login"}}},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(server.URL, "test-api-key")
+	responses, err := client.ChatCompletions("gpt-4o", []Message{{Role: "user", Content: "Hello"}}, 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(responses) != 3 {
+		t.Errorf("Expected 3 responses, got %d", len(responses))
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+}
+
+func TestDeduplicate(t *testing.T) {
+	responses := []string{"Fix login", "  fix   LOGIN\n", "", "Repair login", "Fix login"}
+	expected := []string{"Fix login", "Repair login"}
+
+	result := Deduplicate(responses)
+	if strings.Join(result, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected %v but got %v", expected, result)
+	}
+}
+
+func TestChatCompletionRecordsUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := OpenAIResponse{
+			Choices: []OpenAIChoice{{Message: OpenAIMessage{Role: "assistant", Content: "Fix login"}}},
+			Usage:   &OpenAIUsage{PromptTokens: 120, CompletionTokens: 8},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	before := TotalUsage()
+
+	client, _ := NewClient(server.URL, "test-api-key")
+	if _, err := client.ChatCompletion("gpt-4o", []Message{{Role: "user", Content: "Hello"}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	after := TotalUsage()
+	if after.PromptTokens-before.PromptTokens != 120 || after.CompletionTokens-before.CompletionTokens != 8 {
+		t.Errorf("Expected usage to grow by 120/8 tokens, got %+v -> %+v", before, after)
+	}
+	if after.TotalTokens-before.TotalTokens != 128 {
+		t.Errorf("Expected total usage to grow by 128 tokens, got %d", after.TotalTokens-before.TotalTokens)
+	}
+}
@@ -28,6 +28,7 @@ type OpenAIRequest struct {
 	Messages    []OpenAIMessage `json:"messages"`
 	Temperature float64         `json:"temperature,omitempty"`
 	MaxTokens   int             `json:"max_tokens,omitempty"`
+	N           int             `json:"n,omitempty"`
 }
 
 // OpenAIChoice represents a choice returned by the API
@@ -42,9 +43,16 @@ type OpenAIResponse struct {
 	Object  string         `json:"object"`
 	Created int64          `json:"created"`
 	Choices []OpenAIChoice `json:"choices"`
+	Usage   *OpenAIUsage   `json:"usage,omitempty"`
 	Error   *OpenAIError   `json:"error,omitempty"`
 }
 
+// OpenAIUsage represents the token usage reported by the API
+type OpenAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
 // OpenAIError represents an error returned by the API
 type OpenAIError struct {
 	Message string `json:"message"`
@@ -65,6 +73,21 @@ func NewOpenAIProvider(endpoint, apiKey string) (*OpenAIProvider, error) {
 
 // ChatCompletion sends a chat completion request to the OpenAI-compatible API
 func (p *OpenAIProvider) ChatCompletion(model string, messages []Message) (string, error) {
+	choices, err := p.complete(model, messages, 0)
+	if err != nil {
+		return "", err
+	}
+	return choices[0], nil
+}
+
+// ChatCompletions requests n alternative completions using the "n" parameter.
+// OpenAI-compatible servers that ignore the parameter return a single choice.
+func (p *OpenAIProvider) ChatCompletions(model string, messages []Message, n int) ([]string, error) {
+	return p.complete(model, messages, n)
+}
+
+// complete sends a chat completion request and returns the content of all choices
+func (p *OpenAIProvider) complete(model string, messages []Message, n int) ([]string, error) {
 	// Convert to OpenAI message format
 	openaiMessages := make([]OpenAIMessage, len(messages))
 	for i, msg := range messages {
@@ -80,15 +103,18 @@ func (p *OpenAIProvider) ChatCompletion(model string, messages []Message) (strin
 		Temperature: 0.7,
 		MaxTokens:   1000,
 	}
+	if n > 1 {
+		req.N = n
+	}
 
 	reqBody, err := json.Marshal(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	httpReq, err := http.NewRequest("POST", fmt.Sprintf("%s/chat/completions", p.BaseURL), bytes.NewReader(reqBody))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
@@ -96,7 +122,7 @@ func (p *OpenAIProvider) ChatCompletion(model string, messages []Message) (strin
 
 	resp, err := p.HTTPClient.Do(httpReq)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
@@ -107,25 +133,33 @@ func (p *OpenAIProvider) ChatCompletion(model string, messages []Message) (strin
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
 	}
 
 	var respData OpenAIResponse
 	if err := json.Unmarshal(body, &respData); err != nil {
-		return "", fmt.Errorf("failed to unmarshal response: %w, body: %s", err, string(body))
+		return nil, fmt.Errorf("failed to unmarshal response: %w, body: %s", err, string(body))
 	}
 
 	if respData.Error != nil {
-		return "", fmt.Errorf("API error: %s", respData.Error.Message)
+		return nil, fmt.Errorf("API error: %s", respData.Error.Message)
+	}
+
+	if respData.Usage != nil {
+		RecordUsage(respData.Usage.PromptTokens, respData.Usage.CompletionTokens)
 	}
 
 	if len(respData.Choices) == 0 {
-		return "", fmt.Errorf("no choices returned")
+		return nil, fmt.Errorf("no choices returned")
 	}
 
-	return respData.Choices[0].Message.Content, nil
+	choices := make([]string, len(respData.Choices))
+	for i, choice := range respData.Choices {
+		choices[i] = choice.Message.Content
+	}
+	return choices, nil
 }
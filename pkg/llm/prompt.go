@@ -25,6 +25,45 @@ var branchUserPromptTemplate string
 //go:embed prompts/diff_summary_system.txt
 var diffSummarySystemPromptTemplate string
 
+//go:embed prompts/diff_summary_merge_system.txt
+var diffSummaryMergeSystemPromptTemplate string
+
+//go:embed prompts/split_system.txt
+var splitSystemPromptTemplate string
+
+//go:embed prompts/split_user.txt
+var splitUserPromptTemplate string
+
+//go:embed prompts/squash_user.txt
+var squashUserPromptTemplate string
+
+//go:embed prompts/fixup_system.txt
+var fixupSystemPromptTemplate string
+
+//go:embed prompts/stash_system.txt
+var stashSystemPromptTemplate string
+
+//go:embed prompts/resolve_system.txt
+var resolveSystemPromptTemplate string
+
+//go:embed prompts/resolve_user.txt
+var resolveUserPromptTemplate string
+
+//go:embed prompts/tag_system.txt
+var tagSystemPromptTemplate string
+
+//go:embed prompts/tag_user.txt
+var tagUserPromptTemplate string
+
+//go:embed prompts/tag_bump_system.txt
+var tagBumpSystemPromptTemplate string
+
+//go:embed prompts/summary_system.txt
+var summarySystemPromptTemplate string
+
+//go:embed prompts/summary_user.txt
+var summaryUserPromptTemplate string
+
 // CommitPromptData contains the data to be inserted into the commit prompt template
 type CommitPromptData struct {
 	Diff                    string
@@ -42,6 +81,50 @@ type BranchPromptData struct {
 	Diff           string
 }
 
+// SplitPromptData contains the data to be inserted into the split prompt template
+type SplitPromptData struct {
+	Hunks         string
+	RecentCommits string
+}
+
+// SquashPromptData contains the data to be inserted into the squash prompt template
+type SquashPromptData struct {
+	Diff           string
+	ChangedFiles   string
+	CommitMessages string
+}
+
+// ResolvePromptData contains the data to be inserted into the conflict resolution prompt template
+type ResolvePromptData struct {
+	Path        string
+	Before      string
+	After       string
+	OursLabel   string
+	Ours        string
+	Base        string
+	TheirsLabel string
+	Theirs      string
+}
+
+// TagPromptData contains the data to be inserted into the release notes prompt template
+type TagPromptData struct {
+	Version         string
+	PreviousVersion string
+	CommitMessages  string
+}
+
+// SummaryRepoData contains the commits of one repository for the work summary prompt
+type SummaryRepoData struct {
+	Name    string
+	Commits []string
+}
+
+// SummaryPromptData contains the data to be inserted into the work summary prompt template
+type SummaryPromptData struct {
+	Period string
+	Repos  []SummaryRepoData
+}
+
 // GetSystemPrompt returns the system prompt for commit message generation
 func GetSystemPrompt(useConventionalCommits bool, commitsWithDescriptions bool, isSummarized bool) (string, error) {
 	// Define template functions
@@ -171,11 +254,246 @@ func GetBranchUserPrompt(request string, localBranches, remoteBranches []string,
 	return buf.String(), nil
 }
 
+// GetSplitSystemPrompt returns the system prompt for splitting staged changes into commits
+func GetSplitSystemPrompt(useConventionalCommits bool, commitsWithDescriptions bool) (string, error) {
+	// Define template functions
+	funcMap := template.FuncMap{
+		"trimSpace": strings.TrimSpace,
+	}
+
+	// Parse the template
+	tmpl, err := template.New("splitSystemPrompt").Funcs(funcMap).Parse(splitSystemPromptTemplate)
+	if err != nil {
+		return "", fmt.Errorf("error parsing split system prompt template: %w", err)
+	}
+
+	// Prepare data for the template
+	data := struct {
+		UseConventional         bool
+		CommitsWithDescriptions bool
+	}{
+		UseConventional:         useConventionalCommits,
+		CommitsWithDescriptions: commitsWithDescriptions,
+	}
+
+	// Execute the template
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("error executing split system prompt template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// GetSplitUserPrompt generates a user prompt for splitting staged changes into commits
+func GetSplitUserPrompt(hunks, recentCommits string) (string, error) {
+	// Prepare data for template
+	data := SplitPromptData{
+		Hunks:         hunks,
+		RecentCommits: formatAsList(recentCommits),
+	}
+
+	// Define template functions
+	funcMap := template.FuncMap{
+		"trimSpace": strings.TrimSpace,
+	}
+
+	// Parse and execute the template
+	tmpl, err := template.New("split").Funcs(funcMap).Parse(splitUserPromptTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// GetSquashUserPrompt generates a user prompt for a message describing several squashed commits
+func GetSquashUserPrompt(diff, changedFiles string, commitMessages []string) (string, error) {
+	// Keep only the subject of each message so long bodies don't drown out the diff
+	subjects := make([]string, len(commitMessages))
+	for i, message := range commitMessages {
+		subjects[i], _, _ = strings.Cut(message, "\n")
+	}
+
+	// Prepare data for template
+	data := SquashPromptData{
+		Diff:           diff,
+		ChangedFiles:   formatAsList(changedFiles),
+		CommitMessages: formatAsList(strings.Join(subjects, "\n")),
+	}
+
+	// Define template functions
+	funcMap := template.FuncMap{
+		"trimSpace": strings.TrimSpace,
+	}
+
+	// Parse and execute the template
+	tmpl, err := template.New("squash").Funcs(funcMap).Parse(squashUserPromptTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// GetFixupSystemPrompt returns the system prompt for choosing fixup targets
+func GetFixupSystemPrompt() string {
+	return fixupSystemPromptTemplate
+}
+
+// GetStashSystemPrompt returns the system prompt for stash message generation
+func GetStashSystemPrompt() string {
+	return stashSystemPromptTemplate
+}
+
+// GetResolveSystemPrompt returns the system prompt for merge conflict resolution
+func GetResolveSystemPrompt() string {
+	return resolveSystemPromptTemplate
+}
+
+// GetResolveUserPrompt generates a user prompt for resolving a single merge conflict
+func GetResolveUserPrompt(data ResolvePromptData) (string, error) {
+	// Define template functions
+	funcMap := template.FuncMap{
+		"trimSpace": strings.TrimSpace,
+	}
+
+	// Parse and execute the template
+	tmpl, err := template.New("resolve").Funcs(funcMap).Parse(resolveUserPromptTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// GetTagSystemPrompt returns the system prompt for release notes generation
+func GetTagSystemPrompt() string {
+	return tagSystemPromptTemplate
+}
+
+// GetTagBumpSystemPrompt returns the system prompt for classifying the version bump of a release
+func GetTagBumpSystemPrompt() string {
+	return tagBumpSystemPromptTemplate
+}
+
+// GetTagUserPrompt generates a user prompt for the release notes of a new version
+func GetTagUserPrompt(version, previousVersion string, commitMessages []string) (string, error) {
+	// Separate full messages so multi-line bodies stay attached to their subject
+	formattedMessages := make([]string, len(commitMessages))
+	for i, message := range commitMessages {
+		formattedMessages[i] = "---\n" + strings.TrimSpace(message)
+	}
+
+	// Prepare data for template
+	data := TagPromptData{
+		Version:         version,
+		PreviousVersion: previousVersion,
+		CommitMessages:  strings.Join(formattedMessages, "\n"),
+	}
+
+	// Define template functions
+	funcMap := template.FuncMap{
+		"trimSpace": strings.TrimSpace,
+	}
+
+	// Parse and execute the template
+	tmpl, err := template.New("tag").Funcs(funcMap).Parse(tagUserPromptTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// GetSummarySystemPrompt returns the system prompt for work summaries in the given
+// output format ("markdown", "slack" or "plain")
+func GetSummarySystemPrompt(format string) (string, error) {
+	// Define template functions
+	funcMap := template.FuncMap{
+		"trimSpace": strings.TrimSpace,
+	}
+
+	// Parse the template
+	tmpl, err := template.New("summarySystemPrompt").Funcs(funcMap).Parse(summarySystemPromptTemplate)
+	if err != nil {
+		return "", fmt.Errorf("error parsing summary system prompt template: %w", err)
+	}
+
+	// Prepare data for the template
+	data := struct {
+		Format string
+	}{
+		Format: format,
+	}
+
+	// Execute the template
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("error executing summary system prompt template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// GetSummaryUserPrompt generates a user prompt for summarizing the commits of several repositories
+func GetSummaryUserPrompt(period string, repos []SummaryRepoData) (string, error) {
+	// Prepare data for template
+	data := SummaryPromptData{
+		Period: period,
+		Repos:  repos,
+	}
+
+	// Define template functions
+	funcMap := template.FuncMap{
+		"trimSpace": strings.TrimSpace,
+	}
+
+	// Parse and execute the template
+	tmpl, err := template.New("summary").Funcs(funcMap).Parse(summaryUserPromptTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
 // GetDiffSummarySystemPrompt returns the system prompt for diff summarization
 func GetDiffSummarySystemPrompt() string {
 	return diffSummarySystemPromptTemplate
 }
 
+// GetDiffSummaryMergeSystemPrompt returns the system prompt for condensing diff summaries
+// that are too long together
+func GetDiffSummaryMergeSystemPrompt() string {
+	return diffSummaryMergeSystemPromptTemplate
+}
+
 // Helper function to format a newline-separated string as a list
 func formatAsList(input string) string {
 	lines := strings.Split(input, "\n")
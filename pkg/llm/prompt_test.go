@@ -198,3 +198,67 @@ func TestGetBranchUserPrompt(t *testing.T) {
 		t.Errorf("Expected branch prompt with empty branches to still contain the request")
 	}
 }
+
+func TestGetResolveUserPrompt(t *testing.T) {
+	data := ResolvePromptData{
+		Path:        "src/main.go",
+		Before:      "func main() {",
+		After:       "}",
+		OursLabel:   "HEAD",
+		Ours:        "\tfmt.Println(\"hello\")\n",
+		Base:        "\tfmt.Println(\"hi\")\n",
+		TheirsLabel: "feature",
+		Theirs:      "\tlog.Println(\"hi\")\n",
+	}
+
+	prompt, err := GetResolveUserPrompt(data)
+	if err != nil {
+		t.Errorf("Failed to generate resolve user prompt: %v", err)
+	}
+
+	for _, expected := range []string{"src/main.go", "# Ours (HEAD):", "# Base (common ancestor):", "# Theirs (feature):", "log.Println"} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("Expected resolve prompt to contain %q", expected)
+		}
+	}
+
+	// The base section is omitted when the conflict has no base
+	data.Base = ""
+	prompt, err = GetResolveUserPrompt(data)
+	if err != nil {
+		t.Errorf("Failed to generate resolve user prompt without base: %v", err)
+	}
+	if strings.Contains(prompt, "# Base") {
+		t.Errorf("Expected resolve prompt without base to omit the base section")
+	}
+}
+
+func TestGetSummaryPrompts(t *testing.T) {
+	repos := []SummaryRepoData{
+		{Name: "api", Commits: []string{"2024-05-02 Add rate limiting", "2024-05-02 Fix token refresh"}},
+		{Name: "web", Commits: []string{"2024-05-02 Redesign settings page"}},
+	}
+
+	prompt, err := GetSummaryUserPrompt("since yesterday", repos)
+	if err != nil {
+		t.Errorf("Failed to generate summary user prompt: %v", err)
+	}
+	for _, expected := range []string{"since yesterday", "# Repository: api", "- 2024-05-02 Fix token refresh", "# Repository: web"} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("Expected summary prompt to contain %q", expected)
+		}
+	}
+
+	// Each format has its own formatting instructions
+	slackPrompt, err := GetSummarySystemPrompt("slack")
+	if err != nil {
+		t.Errorf("Failed to generate summary system prompt: %v", err)
+	}
+	markdownPrompt, err := GetSummarySystemPrompt("markdown")
+	if err != nil {
+		t.Errorf("Failed to generate summary system prompt: %v", err)
+	}
+	if !strings.Contains(slackPrompt, "Slack") || strings.Contains(markdownPrompt, "Slack") {
+		t.Errorf("Expected only the slack summary prompt to mention Slack formatting")
+	}
+}
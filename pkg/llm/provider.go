@@ -6,6 +6,13 @@ type Provider interface {
 	ChatCompletion(model string, messages []Message) (string, error)
 }
 
+// MultiCompletionProvider is implemented by providers that can return several
+// alternative completions for a single request
+type MultiCompletionProvider interface {
+	// ChatCompletions requests n alternative completions. Providers may return fewer.
+	ChatCompletions(model string, messages []Message, n int) ([]string, error)
+}
+
 // Message represents a message in the conversation
 type Message struct {
 	Role    string `json:"role"`
@@ -0,0 +1,143 @@
+package llm
+
+import (
+	"sync"
+	"time"
+
+	"github.com/recrsn/git-ai/pkg/logger"
+)
+
+// DefaultConcurrency returns how many requests are sent to a provider at the same time
+// when summarizing. Ollama runs requests one after another, so more would only queue up.
+func DefaultConcurrency(provider string) int {
+	if provider == "ollama" {
+		return 1
+	}
+	return 4
+}
+
+// RateLimiter limits the number of requests and tokens per minute with two token
+// buckets. Waiting reserves capacity, so concurrent callers are served in turn. A nil
+// limiter never waits.
+type RateLimiter struct {
+	mu       sync.Mutex
+	requests *bucket
+	tokens   *bucket
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// bucket is a token bucket that refills continuously up to its capacity
+type bucket struct {
+	capacity  float64
+	available float64
+	// rate is the refill rate per second
+	rate    float64
+	updated time.Time
+}
+
+// NewRateLimiter creates a limiter allowing the given number of requests and tokens per
+// minute. Zero means no limit; without any limit it returns nil.
+func NewRateLimiter(requestsPerMinute, tokensPerMinute int) *RateLimiter {
+	if requestsPerMinute <= 0 && tokensPerMinute <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	return &RateLimiter{
+		requests: newBucket(requestsPerMinute, now),
+		tokens:   newBucket(tokensPerMinute, now),
+		now:      time.Now,
+		sleep:    time.Sleep,
+	}
+}
+
+// newBucket creates a full bucket for a per-minute limit, or nil for no limit
+func newBucket(perMinute int, now time.Time) *bucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &bucket{
+		capacity:  float64(perMinute),
+		available: float64(perMinute),
+		rate:      float64(perMinute) / 60,
+		updated:   now,
+	}
+}
+
+// reserve takes n from the bucket and returns how long to wait until they are available.
+// Requests larger than the bucket take all of it.
+func (b *bucket) reserve(n float64, now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	b.available = min(b.capacity, b.available+now.Sub(b.updated).Seconds()*b.rate)
+	b.updated = now
+	b.available -= min(n, b.capacity)
+	if b.available >= 0 {
+		return 0
+	}
+	return time.Duration(-b.available / b.rate * float64(time.Second))
+}
+
+// Wait blocks until a request with the given number of tokens is allowed
+func (l *RateLimiter) Wait(tokens int) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	now := l.now()
+	delay := max(l.requests.reserve(1, now), l.tokens.reserve(float64(tokens), now))
+	l.mu.Unlock()
+
+	if delay > 0 {
+		logger.Debug("Rate limit reached, waiting %s", delay.Round(time.Millisecond))
+		l.sleep(delay)
+	}
+}
+
+var (
+	limiterMu sync.Mutex
+	limiter   *RateLimiter
+)
+
+// SetRateLimits limits all requests made through a Client to the given number of requests
+// and tokens per minute. Zero means no limit.
+func SetRateLimits(requestsPerMinute, tokensPerMinute int) {
+	limiterMu.Lock()
+	defer limiterMu.Unlock()
+
+	limiter = NewRateLimiter(requestsPerMinute, tokensPerMinute)
+}
+
+// estimatedCompletionTokens is the number of tokens expected for each completion. Providers
+// count generated tokens against the same per-minute limit as the prompt, usually reserving
+// the requested maximum, which is 1000 for OpenAI-compatible requests.
+const estimatedCompletionTokens = 1000
+
+// estimateRequestTokens estimates the tokens a request for the given messages and number
+// of completions uses: the prompt, at about 4 characters per token, and each completion
+func estimateRequestTokens(messages []Message, completions int) int {
+	length := 0
+	for _, message := range messages {
+		length += len(message.Content)
+	}
+	return length/4 + max(completions, 1)*estimatedCompletionTokens
+}
+
+// waitForRateLimit blocks until a request for the given messages, generating the given
+// number of completions, is allowed
+func waitForRateLimit(messages []Message, completions int) {
+	limiterMu.Lock()
+	current := limiter
+	limiterMu.Unlock()
+
+	if current == nil {
+		return
+	}
+
+	current.Wait(estimateRequestTokens(messages, completions))
+}
@@ -0,0 +1,89 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock advances time only when the limiter sleeps
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) limiter(requestsPerMinute, tokensPerMinute int) *RateLimiter {
+	l := NewRateLimiter(requestsPerMinute, tokensPerMinute)
+	l.requests = newBucket(requestsPerMinute, c.now)
+	l.tokens = newBucket(tokensPerMinute, c.now)
+	l.now = func() time.Time { return c.now }
+	l.sleep = func(d time.Duration) {
+		c.sleeps = append(c.sleeps, d)
+		c.now = c.now.Add(d)
+	}
+	return l
+}
+
+func TestRateLimiterRequests(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	limiter := clock.limiter(2, 0)
+
+	limiter.Wait(100)
+	limiter.Wait(100)
+	if len(clock.sleeps) != 0 {
+		t.Fatalf("Expected the first requests to pass, slept %v", clock.sleeps)
+	}
+
+	// The bucket refills one request every 30 seconds
+	limiter.Wait(100)
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != 30*time.Second {
+		t.Errorf("Expected to wait 30s for the third request, slept %v", clock.sleeps)
+	}
+}
+
+func TestRateLimiterTokens(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	limiter := clock.limiter(0, 600)
+
+	limiter.Wait(600)
+	limiter.Wait(300)
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != 30*time.Second {
+		t.Errorf("Expected to wait 30s for 300 more tokens, slept %v", clock.sleeps)
+	}
+
+	// Requests larger than the limit wait for a full bucket instead of forever
+	clock.now = clock.now.Add(time.Hour)
+	clock.sleeps = nil
+	limiter.Wait(5000)
+	limiter.Wait(10)
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != time.Second {
+		t.Errorf("Expected to wait 1s after a large request, slept %v", clock.sleeps)
+	}
+}
+
+func TestEstimateRequestTokens(t *testing.T) {
+	messages := []Message{{Role: "system", Content: strings.Repeat("a", 400)}, {Role: "user", Content: strings.Repeat("b", 400)}}
+
+	if tokens := estimateRequestTokens(messages, 1); tokens != 200+estimatedCompletionTokens {
+		t.Errorf("Expected the prompt and one completion, got %d tokens", tokens)
+	}
+	// Every completion of a request with n > 1 counts
+	if tokens := estimateRequestTokens(messages, 3); tokens != 200+3*estimatedCompletionTokens {
+		t.Errorf("Expected the prompt and three completions, got %d tokens", tokens)
+	}
+}
+
+func TestNewRateLimiterUnlimited(t *testing.T) {
+	limiter := NewRateLimiter(0, 0)
+	if limiter != nil {
+		t.Errorf("Expected no limiter without limits")
+	}
+	// A nil limiter never waits
+	limiter.Wait(1000)
+}
+
+func TestDefaultConcurrency(t *testing.T) {
+	if DefaultConcurrency("ollama") != 1 || DefaultConcurrency("openai") != 4 {
+		t.Errorf("Unexpected default concurrency: ollama %d, openai %d", DefaultConcurrency("ollama"), DefaultConcurrency("openai"))
+	}
+}
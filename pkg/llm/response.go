@@ -0,0 +1,24 @@
+package llm
+
+import (
+	"strings"
+)
+
+// StripCodeFence removes a surrounding Markdown code fence (e.g. ```json ... ```)
+// that models sometimes wrap around structured responses
+func StripCodeFence(response string) string {
+	trimmed := strings.TrimSpace(response)
+	if !strings.HasPrefix(trimmed, "```") {
+		return trimmed
+	}
+
+	// Drop the opening fence line, including any language tag
+	if newline := strings.Index(trimmed, "\n"); newline != -1 {
+		trimmed = trimmed[newline+1:]
+	} else {
+		return ""
+	}
+
+	trimmed = strings.TrimSuffix(strings.TrimSpace(trimmed), "```")
+	return strings.TrimSpace(trimmed)
+}
@@ -0,0 +1,35 @@
+package llm
+
+import (
+	"sync"
+)
+
+// Usage holds the number of tokens consumed by LLM requests
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+var (
+	usageMu    sync.Mutex
+	totalUsage Usage
+)
+
+// RecordUsage adds the token usage reported by a provider to the running total
+func RecordUsage(promptTokens, completionTokens int) {
+	usageMu.Lock()
+	defer usageMu.Unlock()
+
+	totalUsage.PromptTokens += promptTokens
+	totalUsage.CompletionTokens += completionTokens
+	totalUsage.TotalTokens += promptTokens + completionTokens
+}
+
+// TotalUsage returns the token usage of all requests made by this process
+func TotalUsage() Usage {
+	usageMu.Lock()
+	defer usageMu.Unlock()
+
+	return totalUsage
+}
@@ -1,7 +1,9 @@
 package logger
 
 import (
+	"fmt"
 	golog "log"
+	"sync"
 )
 
 // LogLevel represents the severity level of a log message
@@ -23,6 +25,13 @@ const (
 var (
 	// currentLevel controls logging verbosity
 	currentLevel = FATAL
+
+	// warnings collects all warning messages, regardless of the log level
+	warnings   []string
+	warningsMu sync.Mutex
+
+	// fatalHandler is called with the message of a fatal error before the program exits
+	fatalHandler func(message string)
 )
 
 // levelPrefixes maps log levels to text prefixes
@@ -64,8 +73,30 @@ func SetLevelByName(levelName string) {
 	}
 }
 
+// Warnings returns the messages of all warnings logged so far
+func Warnings() []string {
+	warningsMu.Lock()
+	defer warningsMu.Unlock()
+	return append([]string(nil), warnings...)
+}
+
+// SetFatalHandler registers a function that is called with the message of a fatal
+// error before the program exits, e.g. to report it in a machine-readable format
+func SetFatalHandler(handler func(message string)) {
+	fatalHandler = handler
+}
+
 // log prints a log message with the specified level
 func log(level LogLevel, format string, args ...interface{}) {
+	if level == WARN {
+		warningsMu.Lock()
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+		warningsMu.Unlock()
+	}
+	if level == FATAL && fatalHandler != nil {
+		fatalHandler(fmt.Sprintf(format, args...))
+	}
+
 	if level < currentLevel {
 		return
 	}
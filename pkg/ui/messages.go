@@ -2,32 +2,62 @@ package ui
 
 import (
 	"fmt"
+	"os"
 )
 
 // PrintMessage prints an unformatted message to stdout (for user-facing output)
-// User messages always go to stdout with decoration
+// User messages always go to stdout with decoration, or into the result in JSON mode
 func PrintMessage(message string) {
+	if IsJSONOutput() {
+		recordMessage(message)
+		return
+	}
 	fmt.Printf("🤖 %s\n", message)
 }
 
 // PrintMessagef prints a formatted message to stdout (for user-facing output)
-// User messages always go to stdout with decoration
+// User messages always go to stdout with decoration, or into the result in JSON mode
 func PrintMessagef(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
-	fmt.Printf("🤖 %s\n", message)
+	PrintMessage(fmt.Sprintf(format, args...))
 }
 
 // PrintSuccess prints a success message to stdout with decoration
 func PrintSuccess(message string) {
+	if IsJSONOutput() {
+		recordMessage(message)
+		return
+	}
 	fmt.Printf("✅ %s\n", message)
 }
 
-// PrintError prints an error message to stdout with decoration (for user-facing errors)
+// PrintError prints an error message to stdout with decoration (for user-facing errors).
+// In JSON mode the message goes to stderr and into the result's errors, as the command
+// may carry on after it.
 func PrintError(message string) {
+	if IsJSONOutput() {
+		fmt.Fprintf(os.Stderr, "❌ %s\n", message)
+		recordError(message)
+		return
+	}
 	fmt.Printf("❌ %s\n", message)
 }
 
 func PrintErrorf(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
-	fmt.Printf("❌ %s\n", message)
+	PrintError(fmt.Sprintf(format, args...))
+}
+
+// PrintFatal prints an error message like PrintError and exits.
+// In JSON mode the message is reported as the result's error on stdout and on stderr.
+func PrintFatal(message string) {
+	if IsJSONOutput() {
+		fmt.Fprintf(os.Stderr, "❌ %s\n", message)
+		EmitError(message)
+	} else {
+		fmt.Printf("❌ %s\n", message)
+	}
+	os.Exit(1)
+}
+
+func PrintFatalf(format string, args ...interface{}) {
+	PrintFatal(fmt.Sprintf(format, args...))
 }
@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pterm/pterm"
+	"github.com/recrsn/git-ai/pkg/git"
+	"github.com/recrsn/git-ai/pkg/llm"
+	"github.com/recrsn/git-ai/pkg/logger"
+)
+
+const (
+	// OutputText is the default, human-readable output format
+	OutputText = "text"
+	// OutputJSON prints a single JSON object describing the result on stdout
+	OutputJSON = "json"
+)
+
+var (
+	outputFormat = OutputText
+
+	resultMu sync.Mutex
+	result   = map[string]any{}
+	messages []string
+	// errorMessages collects the non-fatal errors shown to the user
+	errorMessages []string
+	emitted       bool
+
+	// reportedSecrets collects the secrets masked during this run
+	reportedSecrets []git.SecretFinding
+)
+
+// SetOutputFormat selects the output format. In JSON mode decorated output goes to
+// stderr, spinners and prompts are disabled and fatal errors are reported as JSON.
+func SetOutputFormat(format string) error {
+	switch format {
+	case OutputText:
+	case OutputJSON:
+		redirectOutput(os.Stderr)
+		logger.SetFatalHandler(func(message string) {
+			EmitError(message)
+		})
+	default:
+		return fmt.Errorf("invalid output format %q, expected text or json", format)
+	}
+
+	outputFormat = format
+	return nil
+}
+
+// redirectOutput sends all decorated pterm output to w. The prefix printers capture
+// the default output when the package is initialized, so they are updated separately.
+func redirectOutput(w io.Writer) {
+	pterm.SetDefaultOutput(w)
+	for _, printer := range []*pterm.PrefixPrinter{&pterm.Info, &pterm.Warning, &pterm.Success, &pterm.Error, &pterm.Fatal, &pterm.Debug, &pterm.Description} {
+		printer.Writer = w
+	}
+}
+
+// IsJSONOutput reports whether results are printed as JSON
+func IsJSONOutput() bool {
+	return outputFormat == OutputJSON
+}
+
+// SetResult sets a field of the JSON result object
+func SetResult(key string, value any) {
+	resultMu.Lock()
+	defer resultMu.Unlock()
+	result[key] = value
+}
+
+// SetMessageResult sets the message, subject and body fields of the JSON result object
+func SetMessageResult(message string) {
+	subject, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	SetResult("message", message)
+	SetResult("subject", strings.TrimSpace(subject))
+	SetResult("body", strings.TrimSpace(body))
+}
+
+// EmitResult prints the JSON result object on stdout, including the messages shown to
+// the user, non-fatal errors, logged warnings and the token usage. It does nothing outside JSON mode or
+// if the result was already printed.
+func EmitResult() {
+	resultMu.Lock()
+	defer resultMu.Unlock()
+
+	if !IsJSONOutput() || emitted {
+		return
+	}
+	emitted = true
+
+	if len(messages) > 0 {
+		result["messages"] = messages
+	}
+	if len(errorMessages) > 0 {
+		result["errors"] = errorMessages
+	}
+	result["warnings"] = append([]string{}, logger.Warnings()...)
+	if usage := llm.TotalUsage(); usage.TotalTokens > 0 {
+		result["usage"] = usage
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding JSON output: %v\n", err)
+	}
+}
+
+// Exit ends the command early with the given status code. The JSON result is printed
+// first, since PersistentPostRun doesn't run after os.Exit.
+func Exit(code int) {
+	EmitResult()
+	os.Exit(code)
+}
+
+// EmitError prints the JSON result object with the given error message
+func EmitError(message string) {
+	SetResult("error", message)
+	EmitResult()
+}
+
+// recordMessage keeps a user-facing message for the JSON result
+func recordMessage(message string) {
+	resultMu.Lock()
+	defer resultMu.Unlock()
+	messages = append(messages, message)
+}
+
+// recordError keeps a non-fatal error shown to the user for the JSON result
+func recordError(message string) {
+	resultMu.Lock()
+	defer resultMu.Unlock()
+	errorMessages = append(errorMessages, message)
+}
@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"errors"
+	"os"
+
+	"github.com/pterm/pterm"
+	"golang.org/x/term"
+)
+
+// ErrNonInteractive is returned by prompts when the user cannot be asked for input
+var ErrNonInteractive = errors.New("cannot prompt for input in a non-interactive session (use --yes to accept generated results automatically)")
+
+var (
+	// stdoutIsTerminal is false when output is piped or redirected, e.g. in CI
+	stdoutIsTerminal = term.IsTerminal(int(os.Stdout.Fd()))
+	// stdinIsTerminal is false when input can't come from a keyboard
+	stdinIsTerminal = term.IsTerminal(int(os.Stdin.Fd()))
+
+	// nonInteractive is set by --yes/--non-interactive
+	nonInteractive bool
+)
+
+// ConfigureTerminal adapts the output to the environment: styling and animations are
+// disabled when stdout is not a terminal, and colors when NO_COLOR is set.
+func ConfigureTerminal() {
+	if !stdoutIsTerminal {
+		pterm.DisableStyling()
+	} else if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		pterm.DisableColor()
+	}
+}
+
+// SetNonInteractive disables all prompts, as requested with --yes/--non-interactive
+func SetNonInteractive() {
+	nonInteractive = true
+}
+
+// IsInteractive reports whether the user can be prompted for input
+func IsInteractive() bool {
+	return stdinIsTerminal && stdoutIsTerminal && !nonInteractive && !IsJSONOutput()
+}
+
+// spinnersEnabled reports whether progress spinners can be animated
+func spinnersEnabled() bool {
+	return stdoutIsTerminal && !IsJSONOutput()
+}
@@ -3,46 +3,159 @@ package ui
 import (
 	"fmt"
 	"os"
+	"strings"
+	"sync"
 
 	"github.com/pterm/pterm"
 	"github.com/recrsn/git-ai/pkg/git"
+	"github.com/recrsn/git-ai/pkg/llm"
 )
 
+// ConfirmationOptions enables additional actions when confirming a generated message
+type ConfirmationOptions struct {
+	// Regenerate generates a new message, offered as "Regenerate" when set
+	Regenerate func() (string, error)
+	// Alternatives generates several alternative messages, offered as "Show alternatives" when set
+	Alternatives func() ([]string, error)
+	// Refine regenerates the message following an instruction from the user, offered as "Refine" when set
+	Refine func(message, instruction string) (string, error)
+}
+
 // PromptForConfirmation asks the user to confirm, edit, or cancel the commit message
 func PromptForConfirmation(message string) (string, bool) {
-	// Display the generated message with styling
-	pterm.DefaultBox.WithTitle("Generated Commit Message").WithTitleBottomRight().Print(message)
-	pterm.Println()
+	return PromptForConfirmationWithOptions(message, ConfirmationOptions{})
+}
 
-	// Create interactive select menu
-	options := []string{"Approve", "Edit", "Cancel"}
-	selectedOption, err := pterm.DefaultInteractiveSelect.
-		WithOptions(options).
-		WithDefaultText("What would you like to do?").
-		Show()
+// PromptForConfirmationWithOptions asks the user to confirm, edit, or cancel the commit message,
+// offering to refine, regenerate or pick an alternative if the options provide a way to do so.
+// It keeps asking until the message is approved, edited or cancelled.
+func PromptForConfirmationWithOptions(message string, opts ConfirmationOptions) (string, bool) {
+	if !IsInteractive() {
+		ExitWithError(ErrNonInteractive.Error())
+	}
 
-	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		return "", false
+	options := []string{"Approve", "Edit"}
+	if opts.Refine != nil {
+		options = append(options, "Refine")
+	}
+	if opts.Regenerate != nil {
+		options = append(options, "Regenerate")
 	}
+	if opts.Alternatives != nil {
+		options = append(options, "Show alternatives")
+	}
+	options = append(options, "Cancel")
+
+	for {
+		// Display the generated message with styling
+		pterm.DefaultBox.WithTitle("Generated Commit Message").WithTitleBottomRight().Print(message)
+		pterm.Println()
+
+		// Create interactive select menu
+		selectedOption, err := pterm.DefaultInteractiveSelect.
+			WithOptions(options).
+			WithDefaultText("What would you like to do?").
+			Show()
 
-	switch selectedOption {
-	case "Approve":
-		return message, true
-	case "Edit":
-		// Use the external editor
-		editedMessage, err := git.EditWithExternalEditor(message)
 		if err != nil {
-			fmt.Printf("Error opening external editor: %v\n", err)
+			fmt.Printf("Error: %v\n", err)
 			return "", false
 		}
-		return editedMessage, true
-	case "Cancel":
-		pterm.Println("Commit cancelled.")
-		return "", false
+
+		switch selectedOption {
+		case "Approve":
+			return message, true
+		case "Edit":
+			// Use the external editor
+			editedMessage, err := git.EditWithExternalEditor(message)
+			if err != nil {
+				fmt.Printf("Error opening external editor: %v\n", err)
+				return "", false
+			}
+			return editedMessage, true
+		case "Refine":
+			instruction, err := PromptForInput("How should the message change? (e.g. \"mention the migration\", \"shorter\")", "")
+			if err != nil {
+				PrintErrorf("Error reading instruction: %v", err)
+				continue
+			}
+			instruction = strings.TrimSpace(instruction)
+			if instruction == "" {
+				continue
+			}
+			refined, err := WithSpinnerResult("Refining commit message with LLM...", func() (string, error) {
+				return opts.Refine(message, instruction)
+			})
+			if err != nil {
+				PrintErrorf("Failed to refine message: %v", err)
+				continue
+			}
+			message = refined
+		case "Regenerate":
+			regenerated, err := WithSpinnerResult("Regenerating commit message with LLM...", opts.Regenerate)
+			if err != nil {
+				PrintErrorf("Failed to regenerate message: %v", err)
+				continue
+			}
+			message = regenerated
+		case "Show alternatives":
+			alternatives, err := WithSpinnerResult("Generating alternatives with LLM...", opts.Alternatives)
+			if err != nil {
+				PrintErrorf("Failed to generate alternatives: %v", err)
+				continue
+			}
+			picked, err := PromptForCandidate(append([]string{message}, alternatives...))
+			if err != nil {
+				PrintErrorf("Error selecting message: %v", err)
+				continue
+			}
+			message = picked
+		case "Cancel":
+			pterm.Println("Commit cancelled.")
+			return "", false
+		}
+	}
+}
+
+// PromptForCandidate shows numbered candidate messages and lets the user pick one.
+// Duplicates are removed with llm.Deduplicate before showing them; a single candidate is
+// returned as is.
+func PromptForCandidate(candidates []string) (string, error) {
+	if !IsInteractive() {
+		return "", ErrNonInteractive
+	}
+
+	unique := llm.Deduplicate(candidates)
+	if len(unique) == 0 {
+		return "", fmt.Errorf("no candidates to choose from")
+	}
+	if len(unique) == 1 {
+		return unique[0], nil
 	}
 
-	return "", false
+	options := make([]string, len(unique))
+	for i, candidate := range unique {
+		pterm.DefaultBox.WithTitle(fmt.Sprintf("Candidate %d", i+1)).WithTitleBottomRight().Print(candidate)
+		pterm.Println()
+
+		subject, _, _ := strings.Cut(strings.TrimSpace(candidate), "\n")
+		options[i] = fmt.Sprintf("%d. %s", i+1, subject)
+	}
+
+	selected, err := pterm.DefaultInteractiveSelect.
+		WithOptions(options).
+		WithDefaultText("Which message would you like to use?").
+		Show()
+	if err != nil {
+		return "", err
+	}
+
+	for i, option := range options {
+		if option == selected {
+			return unique[i], nil
+		}
+	}
+	return unique[0], nil
 }
 
 // DisplayHeader shows a styled header
@@ -77,8 +190,88 @@ func DisplayBox(title, content string) {
 	pterm.Println()
 }
 
+// ShowPrompt prints the messages that are sent to the LLM and an estimate of their token count.
+// The prompt goes to stdout without decoration so that it can be saved or piped.
+func ShowPrompt(messages []llm.Message) {
+	tokens := 0
+	for _, message := range messages {
+		tokens += git.EstimateTokens(message.Content)
+	}
+
+	SetResult("prompt", messages)
+	SetResult("estimated_tokens", tokens)
+	if IsJSONOutput() {
+		return
+	}
+
+	for _, message := range messages {
+		fmt.Printf("===== %s =====\n%s\n\n", message.Role, strings.TrimRight(message.Content, "\n"))
+	}
+	fmt.Printf("Estimated tokens: %d\n", tokens)
+}
+
+// ReportSecrets warns about possible secrets that were masked before prompting the LLM.
+// If block is set it exits with an error instead, so that nothing is sent.
+func ReportSecrets(findings []git.SecretFinding, block bool) {
+	if len(findings) == 0 {
+		return
+	}
+
+	reportedSecrets = append(reportedSecrets, findings...)
+	SetResult("secrets", reportedSecrets)
+
+	locations := make([]string, len(findings))
+	for i, finding := range findings {
+		locations[i] = "  " + finding.String()
+	}
+
+	if block {
+		ExitWithError(fmt.Sprintf("Found %d possible secrets in the changes:\n%s\nRemove them, or unset block_on_secrets to send the changes with secrets masked.",
+			len(findings), strings.Join(locations, "\n")))
+	}
+	pterm.Warning.Printfln("Masked %d possible secrets before sending the changes to the LLM:\n%s", len(findings), strings.Join(locations, "\n"))
+}
+
+// DisplaySideBySide shows two titled boxes next to each other, truncating long lines to fit the terminal
+func DisplaySideBySide(leftTitle, leftContent, rightTitle, rightContent string) {
+	// Leave room for the box borders and the gap between the panels
+	maxWidth := pterm.GetTerminalWidth()/2 - 6
+	left := pterm.DefaultBox.WithTitle(leftTitle).Sprint(truncateLines(leftContent, maxWidth))
+	right := pterm.DefaultBox.WithTitle(rightTitle).Sprint(truncateLines(rightContent, maxWidth))
+
+	_ = pterm.DefaultPanel.WithPanels(pterm.Panels{
+		{{Data: left}, {Data: right}},
+	}).Render()
+}
+
+// truncateLines shortens every line of text to at most maxWidth runes
+func truncateLines(text string, maxWidth int) string {
+	if maxWidth <= 1 {
+		return text
+	}
+
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		if runes := []rune(line); len(runes) > maxWidth {
+			line = string(runes[:maxWidth-1]) + "…"
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DisplayTable shows rows of text as a table, using the first row as the header
+func DisplayTable(rows [][]string) {
+	_ = pterm.DefaultTable.WithHasHeader().WithData(rows).Render()
+	pterm.Println()
+}
+
 // PromptForSelection shows a selection menu and returns the selected option
 func PromptForSelection(options []string, defaultOption string, promptText string) (string, error) {
+	if !IsInteractive() {
+		return "", ErrNonInteractive
+	}
 	return pterm.DefaultInteractiveSelect.
 		WithOptions(options).
 		WithDefaultOption(defaultOption).
@@ -88,6 +281,9 @@ func PromptForSelection(options []string, defaultOption string, promptText strin
 
 // PromptForInput shows a text input prompt and returns the entered text
 func PromptForInput(promptText string, defaultValue string) (string, error) {
+	if !IsInteractive() {
+		return "", ErrNonInteractive
+	}
 	return pterm.DefaultInteractiveTextInput.
 		WithDefaultValue(defaultValue).
 		Show(promptText)
@@ -95,6 +291,9 @@ func PromptForInput(promptText string, defaultValue string) (string, error) {
 
 // PromptForPassword shows a masked text input prompt for passwords
 func PromptForPassword(promptText string) (string, error) {
+	if !IsInteractive() {
+		return "", ErrNonInteractive
+	}
 	return pterm.DefaultInteractiveTextInput.
 		WithMask("•").
 		Show(promptText)
@@ -107,12 +306,19 @@ func ShowSpinner(text string) (*pterm.SpinnerPrinter, error) {
 
 // ExitWithError displays an error message and exits
 func ExitWithError(text string) {
+	if IsJSONOutput() {
+		EmitError(text)
+	}
 	pterm.Error.Println(text)
 	os.Exit(1)
 }
 
 // WithSpinner runs an operation with a spinner and handles success/failure
 func WithSpinner(message string, operation func() error) error {
+	if !spinnersEnabled() {
+		return operation()
+	}
+
 	spinner, err := pterm.DefaultSpinner.Start(message)
 	if err != nil {
 		return fmt.Errorf("failed to start spinner: %w", err)
@@ -130,6 +336,10 @@ func WithSpinner(message string, operation func() error) error {
 
 // WithSpinnerResult runs an operation with a spinner and returns both result and error
 func WithSpinnerResult[T any](message string, operation func() (T, error)) (T, error) {
+	if !spinnersEnabled() {
+		return operation()
+	}
+
 	var zero T
 	spinner, err := pterm.DefaultSpinner.Start(message)
 	if err != nil {
@@ -145,3 +355,31 @@ func WithSpinnerResult[T any](message string, operation func() (T, error)) (T, e
 	spinner.Success("Done!")
 	return result, nil
 }
+
+// WithProgressSpinner runs an operation with a spinner whose text the operation can update
+// through the given function, e.g. to report progress. Updates are ignored without spinners.
+func WithProgressSpinner[T any](message string, operation func(update func(text string)) (T, error)) (T, error) {
+	if !spinnersEnabled() {
+		return operation(func(string) {})
+	}
+
+	var zero T
+	spinner, err := pterm.DefaultSpinner.Start(message)
+	if err != nil {
+		return zero, fmt.Errorf("failed to start spinner: %w", err)
+	}
+
+	var mu sync.Mutex
+	result, err := operation(func(text string) {
+		mu.Lock()
+		defer mu.Unlock()
+		spinner.UpdateText(text)
+	})
+	if err != nil {
+		spinner.Fail(fmt.Sprintf("Failed: %v", err))
+		return zero, err
+	}
+
+	spinner.Success("Done!")
+	return result, nil
+}